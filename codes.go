@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Code is a stable machine readable error code.
+// Clients are supposed to branch on codes rather than on error messages.
+type Code string
+
+// coder is implemented by errors that carry an error code.
+type coder interface {
+	Code() Code
+}
+
+// codedError is a sentinel error with a code.
+type codedError struct {
+	code Code
+	msg  string
+}
+
+// Error returns an error message.
+func (e *codedError) Error() string { return e.msg }
+
+// Code returns the error code.
+func (e *codedError) Code() Code { return e.code }
+
+// NewCoded returns a new error with the code attached.
+// It is used to declare coded sentinels:
+//
+//	var ErrNotFound = errors.NewCoded("not_found", "entity not found")
+func NewCoded(code Code, format string, args ...interface{}) error {
+	return &codedError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// WithCode returns an error with the code attached.
+// The code doesn't affect the error message and can be looked up with CodeOf() or InnermostCodeOf().
+func WithCode(err error, code Code) error {
+	if isErrNil(err) {
+		return nil
+	}
+
+	q := newQueue(err)
+	q.code = code
+
+	return q
+}
+
+// CodeOf returns the outermost error code found in the error and all errors wrapped into it.
+// Returns an empty code if there is none.
+func CodeOf(err error) Code {
+	codes := collectCodes(err, true)
+	if len(codes) == 0 {
+		return ""
+	}
+
+	return codes[0]
+}
+
+// InnermostCodeOf returns the innermost error code found in the error and all errors wrapped into it.
+// Returns an empty code if there is none.
+func InnermostCodeOf(err error) Code {
+	codes := collectCodes(err, false)
+	if len(codes) == 0 {
+		return ""
+	}
+
+	return codes[len(codes)-1]
+}
+
+// collectCodes returns error codes in the outer-to-inner order.
+func collectCodes(err error, returnFirst bool) (codes []Code) {
+	Walk(err, func(err error, _ int) bool {
+		var code Code
+		if q, ok := err.(*queue); ok {
+			code = q.code
+		} else if c, ok := err.(coder); ok {
+			code = c.Code()
+		}
+
+		if code != "" {
+			codes = append(codes, code)
+		}
+
+		return !returnFirst || len(codes) == 0
+	})
+
+	return codes
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewCoded(t *testing.T) {
+	err := NewCoded("not_found", "user %d not found", 42)
+
+	if err.Error() != "user 42 not found" {
+		t.Errorf("NewCoded() error message mismatch, %q != %q", "user 42 not found", err.Error())
+	}
+	if c, ok := err.(coder); !ok || c.Code() != "not_found" {
+		t.Errorf("NewCoded() must provide the %q code, got %v", "not_found", err)
+	}
+	if err == NewCoded("not_found", "user %d not found", 42) {
+		t.Errorf("NewCoded() must return a new error instance")
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	err1 := New("1")
+
+	if err := WithCode(nil, "code"); err != nil {
+		t.Errorf("WithCode(nil, code) must return nil, got %v", err)
+	}
+
+	err := WithCode(err1, "code")
+	if err.Error() != err1.Error() {
+		t.Errorf("WithCode(%v, code) mustn't change the error message, got %q", err1, err)
+	}
+	if Fetch(err, err1) != err1 {
+		t.Errorf("WithCode(%v, code) must keep the error inspectable", err1)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	var (
+		err1         = New("1")
+		errNotFound  = NewCoded("not_found", "not found")
+		errForbidden = NewCoded("forbidden", "forbidden")
+	)
+
+	tcs := []struct {
+		name                 string
+		err                  error
+		outermost, innermost Code
+	}{
+		{
+			name:      "ForANilError",
+			err:       nil,
+			outermost: "", innermost: "",
+		},
+		{
+			name:      "ForAnErrorWithoutCodes",
+			err:       Wrap(err1, New("2")),
+			outermost: "", innermost: "",
+		},
+		{
+			name:      "ForACodedSentinel",
+			err:       errNotFound,
+			outermost: "not_found", innermost: "not_found",
+		},
+		{
+			name:      "ForAWrappedCodedSentinel",
+			err:       WithMessage(Wrap(err1, errNotFound), "message"),
+			outermost: "not_found", innermost: "not_found",
+		},
+		{
+			name:      "ForSeveralCodedSentinels",
+			err:       Wrap(errNotFound, errForbidden),
+			outermost: "forbidden", innermost: "not_found",
+		},
+		{
+			name:      "ForAnAttachedCode",
+			err:       WithCode(Wrap(err1, errNotFound), "internal"),
+			outermost: "internal", innermost: "not_found",
+		},
+		{
+			name:      "ForACodeWrappedByTheStdlib",
+			err:       Wrap(err1, fmt.Errorf("context: %w", errForbidden)),
+			outermost: "forbidden", innermost: "forbidden",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if code := CodeOf(tc.err); code != tc.outermost {
+				t.Errorf("CodeOf(%v) != %q, got %q", tc.err, tc.outermost, code)
+			}
+			if code := InnermostCodeOf(tc.err); code != tc.innermost {
+				t.Errorf("InnermostCodeOf(%v) != %q, got %q", tc.err, tc.innermost, code)
+			}
+		})
+	}
+}
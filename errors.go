@@ -10,12 +10,15 @@
 // error queue instance.
 //
 // Once the error reaches to layer to handle the error, one of functions Fetch(), FetchByType() or FetchAllByType()
-// should be used to examine the error on having one of contexts.
+// should be used to examine the error on having one of contexts. The error queue also implements Unwrap() []error,
+// Is() and As(), so the standard library errors.Is() and errors.As() functions are able to inspect it as well.
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"reflect"
+	"slices"
 )
 
 // New returns an error.
@@ -32,41 +35,10 @@ func New(format string, args ...interface{}) error {
 //
 // This function wraps several errors into internal queue object that conforms to the error interface and is inspectable
 // by Fetch(), FetchByType(), FetchAllByType() functions. It is used to provide extended local context to the existing
-// error object. If any of errors is a queue instance, then Wrap() fetches it's error list and puts to the newly created
-// queue instance.
+// error object. If any of errors is a queue instance, then it becomes a child node of the newly created queue, so the
+// wrapping hierarchy is preserved and can be traversed with Walk().
 func Wrap(errs ...error) error {
-	var (
-		q                = newQueue()
-		foundQs          int
-		foundQStacktrace fmt.Formatter
-	)
-	for _, err := range errs {
-		if isErrNil(err) {
-			continue
-		}
-
-		errQ, ok := err.(*queue)
-		if !ok {
-			q.errs = append(q.errs, err)
-			continue
-		}
-
-		q.errs = append(q.errs, errQ.errs...)
-		if foundQs == 0 {
-			foundQStacktrace = errQ.stacktrace
-		}
-		foundQs++
-	}
-
-	if len(q.errs) == 0 {
-		return nil
-	}
-
-	if foundQs == 1 {
-		q.stacktrace = foundQStacktrace
-	}
-
-	return q
+	return wrap(newQueue(), errs...)
 }
 
 // WithMessage returns an error wrapped with message.
@@ -76,7 +48,9 @@ func WithMessage(err error, format string, args ...interface{}) error {
 		return nil
 	}
 
-	return Wrap(err, New(format, args...))
+	msgErr := New(format, args...)
+
+	return setMessage(wrap(newQueue(), err, msgErr), msgErr)
 }
 
 // WrapWithMessage wraps two errors with message.
@@ -87,25 +61,128 @@ func WrapWithMessage(err1, err2 error, format string, args ...interface{}) error
 		return nil
 	}
 
-	return Wrap(err1, err2, New(format, args...))
+	msgErr := New(format, args...)
+
+	return setMessage(wrap(newQueue(), err1, err2, msgErr), msgErr)
+}
+
+// Messages returns the messages attached to the error with WithMessage() and WrapWithMessage() in the outer-to-inner
+// order.
+func Messages(err error) (msgs []string) {
+	Walk(err, func(err error, _ int) bool {
+		if q, ok := err.(*queue); ok && q.msg != nil {
+			msgs = append(msgs, q.msg.Error())
+		}
+
+		return true
+	})
+
+	return msgs
+}
+
+// wrap appends not nil errors to the q queue.
+// Returns nil if there are no errors to wrap. Exported functions create queues with newQueue() themselves, so the
+// stacktrace starts with the exported function and its caller.
+func wrap(q *queue, errs ...error) error {
+	for _, err := range errs {
+		if isErrNil(err) {
+			continue
+		}
+
+		q.errs = append(q.errs, err)
+	}
+
+	if len(q.errs) == 0 {
+		return nil
+	}
+
+	return q
+}
+
+// setMessage marks msgErr as the message attached to the err queue.
+func setMessage(err, msgErr error) error {
+	if q, ok := err.(*queue); ok && !isErrNil(msgErr) {
+		q.msg = msgErr
+	}
+
+	return err
 }
 
 // Fetch returns targetErr from the err queue.
 // Provided that qErr is an error queue, this function iterates over all queue errors and returns the first matched one.
+// Errors are matched by identity in terms of errors.Is(), so two different errors with the same message don't match.
 func Fetch(qErr, targetErr error) error {
-	if isErrNil(qErr) || isErrNil(targetErr) {
+	return fetch(qErr, targetErr, compareErrs)
+}
+
+// FetchByMessage returns targetErr from the err queue matching errors by their messages.
+// It is an opt-in for the errors that can't be matched by identity, e.g. the ones recreated from a text.
+func FetchByMessage(qErr, targetErr error) error {
+	return fetch(qErr, targetErr, compareErrMessages)
+}
+
+// FetchAny returns the first of targetErrs found in the err queue in terms of Fetch().
+// The queue is inspected once in the outer-to-inner order, so the target matching the outermost error is returned
+// regardless of the targets order.
+func FetchAny(qErr error, targetErrs ...error) error {
+	var matchedErr error
+	FetchFunc(qErr, func(err error) bool {
+		for _, targetErr := range targetErrs {
+			if !isErrNil(targetErr) && compareErrs(err, targetErr) {
+				matchedErr = targetErr
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return matchedErr
+}
+
+// FetchFunc returns the first error from the err queue for which matches returns true.
+// Errors are passed to matches in the outer-to-inner order, the wrapped queues are expanded.
+func FetchFunc(qErr error, matches func(err error) bool) error {
+	errs := fetchAllFunc(qErr, matches, true)
+	if errs == nil {
 		return nil
 	}
 
-	var errs []error
-	if q, ok := qErr.(*queue); ok {
-		errs = q.getErrors()
-	} else {
-		errs = []error{qErr}
+	return errs[0]
+}
+
+// FetchAllFunc returns all errors from the err queue for which matches returns true in the outer-to-inner order.
+func FetchAllFunc(qErr error, matches func(err error) bool) []error {
+	return fetchAllFunc(qErr, matches, false)
+}
+
+func fetchAllFunc(qErr error, matches func(err error) bool, returnFirst bool) (errs []error) {
+	if isErrNil(qErr) {
+		return nil
 	}
 
-	for _, err := range errs {
-		if compareErrs(err, targetErr) {
+	for _, err := range collectErrors(qErr) {
+		if !matches(err) {
+			continue
+		}
+
+		if returnFirst {
+			return []error{err}
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func fetch(qErr, targetErr error, matches func(sourceErr, targetErr error) bool) error {
+	if isErrNil(qErr) || isErrNil(targetErr) {
+		return nil
+	}
+
+	for _, err := range collectErrors(qErr) {
+		if matches(err, targetErr) {
 			return targetErr
 		}
 	}
@@ -130,20 +207,60 @@ func FetchAllByType(qErr error, targetErr interface{}) []error {
 	return fetchAllByType(qErr, targetErr, false)
 }
 
+// FetchAs returns a first error from the error queue that is of type T.
+// T is either an error type, a pointer to an error type or an interface. Unlike FetchByType() it doesn't need a
+// reflection target and returns the error typed. A value type T matches the not nil pointers to T as well.
+func FetchAs[T any](err error) (T, bool) {
+	if errs := fetchAll[T](err, true); len(errs) > 0 {
+		return errs[0], true
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// FetchAll returns all errors from the error queue that are of type T in terms of FetchAs().
+func FetchAll[T any](err error) []T {
+	return fetchAll[T](err, false)
+}
+
+func fetchAll[T any](qErr error, returnFirst bool) (errs []T) {
+	if isErrNil(qErr) {
+		return nil
+	}
+
+	for _, e := range collectErrors(qErr) {
+		var v T
+		switch typedErr := interface{}(e).(type) {
+		case T:
+			v = typedErr
+		case *T:
+			if typedErr == nil {
+				continue
+			}
+			v = *typedErr
+		default:
+			continue
+		}
+
+		if returnFirst {
+			return []T{v}
+		}
+
+		errs = append(errs, v)
+	}
+
+	return errs
+}
+
 func fetchAllByType(qErr error, targetErr interface{}, returnFirst bool) (errs []error) {
 	targetType, targetElem, err := getTypeElem(targetErr)
 	if isErrNil(qErr) || err != nil || targetType.Kind() != reflect.Ptr {
 		return nil
 	}
 
-	var qErrs []error
-	if q, ok := qErr.(*queue); ok {
-		qErrs = q.getErrors()
-	} else {
-		qErrs = []error{qErr}
-	}
-
-	for _, e := range qErrs {
+	for _, e := range collectErrors(qErr) {
 		if !errorMatches(e, targetType, targetElem) {
 			continue
 		}
@@ -162,6 +279,61 @@ func fetchAllByType(qErr error, targetErr interface{}, returnFirst bool) (errs [
 	return nil
 }
 
+// collectErrors returns all errors contained in err in the outer-to-inner order.
+// Error queues are expanded into their errors and errors wrapped by means of the standard library (Unwrap() error or
+// Unwrap() []error) are followed, so the Fetch functions see the same errors as errors.Is() and errors.As() do.
+func collectErrors(err error) []error {
+	return slices.Collect(All(err))
+}
+
+// unwrapErr returns not nil errors directly wrapped by err either with Unwrap() []error or Unwrap() error.
+func unwrapErr(err error) (errs []error) {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		errs = e.Unwrap()
+	case interface{ Unwrap() error }:
+		errs = []error{e.Unwrap()}
+	}
+
+	wrappedErrs := errs[:0:0]
+	for _, wrappedErr := range errs {
+		if !isErrNil(wrappedErr) {
+			wrappedErrs = append(wrappedErrs, wrappedErr)
+		}
+	}
+
+	return wrappedErrs
+}
+
+// Walk traverses the wrapping hierarchy of err in the outer-to-inner order.
+//
+// Each Wrap() call produces a node which children are the wrapped errors. Walk calls fn for every node and every
+// wrapped error with the depth of wrapping, starting with err itself at depth 0. Node errors implement
+// Unwrap() []error returning their children. Errors wrapped by means of the standard library are visited as children
+// of the wrapping error. The traversal stops as soon as fn returns false.
+func Walk(err error, fn func(err error, depth int) bool) {
+	if isErrNil(err) {
+		return
+	}
+
+	walk(err, 0, fn)
+}
+
+// walk visits err and its children and returns false if the traversal was stopped.
+func walk(err error, depth int, fn func(err error, depth int) bool) bool {
+	if !fn(err, depth) {
+		return false
+	}
+
+	for _, child := range unwrapErr(err) {
+		if !walk(child, depth+1, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // isErrNil returns true if error object is nil.
 func isErrNil(err error) bool {
 	if err == nil {
@@ -177,7 +349,13 @@ func isErrNil(err error) bool {
 // compareErrs returns true if errors are the same.
 // Method contract: sourceErr and targetErr are not nil.
 func compareErrs(sourceErr, targetErr error) bool {
-	return sourceErr.Error() == targetErr.Error()
+	return stderrors.Is(sourceErr, targetErr)
+}
+
+// compareErrMessages returns true if errors are the same or have the same message.
+// Method contract: sourceErr and targetErr are not nil.
+func compareErrMessages(sourceErr, targetErr error) bool {
+	return compareErrs(sourceErr, targetErr) || sourceErr.Error() == targetErr.Error()
 }
 
 // errorMatches returns true if targetErr matches sourceErr.
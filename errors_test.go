@@ -97,6 +97,16 @@ func TestFetch(t *testing.T) {
 			qErr: &queue{errs: []error{err1, err2}}, targetErr: err2,
 			fetchedErr: err2,
 		},
+		{
+			name: "ForAnErrorQueueWithMatchingErrorWrappedByTheStdlib",
+			qErr: &queue{errs: []error{err1, fmt.Errorf("context: %w", err2)}}, targetErr: err2,
+			fetchedErr: err2,
+		},
+		{
+			name: "ForAnErrorQueueWrappedByTheStdlib",
+			qErr: fmt.Errorf("context: %w", &queue{errs: []error{err1, err2}}), targetErr: err1,
+			fetchedErr: err1,
+		},
 	}
 
 	for i := range tcs {
@@ -436,6 +446,12 @@ func TestFetchByType(t *testing.T) {
 			target: (*customError)(nil),
 			res:    nil,
 		},
+		{
+			name:   "ForAnErrorWrappedByTheStdlib",
+			source: &queue{errs: []error{err1, fmt.Errorf("context: %w", customError{"2"})}},
+			target: (*customError)(nil),
+			res:    customError{"2"},
+		},
 		{
 			name:   "ForAnErrorSelectedByTypePointer",
 			source: customError{"2"},
@@ -503,6 +519,21 @@ func TestCompareErrors(t *testing.T) {
 			sourceErr: err2, targetErr: err1,
 			matched: false,
 		},
+		{
+			name:      "ForDifferentErrorsWithTheSameMessage",
+			sourceErr: New("not found"), targetErr: New("not found"),
+			matched: false,
+		},
+		{
+			name:      "ForADynamicErrorWithTheSentinelMessage",
+			sourceErr: New("%s", err1), targetErr: err1,
+			matched: false,
+		},
+		{
+			name:      "ForEqualComparableErrors",
+			sourceErr: customError{"1"}, targetErr: customError{"1"},
+			matched: true,
+		},
 		{
 			name:      "ForAnErrorAndAMatchingErrorQueueAsSource",
 			sourceErr: &queue{errs: []error{err1}}, targetErr: err1,
@@ -511,7 +542,7 @@ func TestCompareErrors(t *testing.T) {
 		{
 			name:      "ForAnErrorAndAMatchingErrorQueueAsTarget",
 			sourceErr: err1, targetErr: &queue{errs: []error{err1}},
-			matched: true,
+			matched: false,
 		},
 		{
 			name:      "ForAnErrorAndANotMatchingErrorQueueAsSource",
@@ -532,6 +563,72 @@ func TestCompareErrors(t *testing.T) {
 	}
 }
 
+func TestCompareErrMessages(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+	)
+
+	tcs := []struct {
+		name                 string
+		sourceErr, targetErr error
+		matched              bool
+	}{
+		{
+			name:      "ForMatchingErrors",
+			sourceErr: err1, targetErr: err1,
+			matched: true,
+		},
+		{
+			name:      "ForNotMatchingErrors",
+			sourceErr: err2, targetErr: err1,
+			matched: false,
+		},
+		{
+			name:      "ForDifferentErrorsWithTheSameMessage",
+			sourceErr: New("not found"), targetErr: New("not found"),
+			matched: true,
+		},
+		{
+			name:      "ForAnErrorAndAMatchingErrorQueueAsTarget",
+			sourceErr: err1, targetErr: &queue{errs: []error{err1}},
+			matched: true,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			match := compareErrMessages(tc.sourceErr, tc.targetErr)
+
+			if tc.matched != match {
+				t.Errorf("compareErrMessages(%v, %v) != %v", tc.sourceErr, tc.targetErr, tc.matched)
+			}
+		})
+	}
+}
+
+func TestFetchForMessageCollisions(t *testing.T) {
+	var (
+		errNotFoundA = New("not found")
+		errNotFoundB = New("not found")
+		q            = Wrap(New("db error"), errNotFoundA)
+	)
+
+	if err := Fetch(q, errNotFoundB); err != nil {
+		t.Errorf("Fetch(%v, %v) must not match an error with the same message, got %v", q, errNotFoundB, err)
+	}
+	if err := Fetch(q, errNotFoundA); err != errNotFoundA {
+		t.Errorf("Fetch(%v, %v) != %v, got %v", q, errNotFoundA, errNotFoundA, err)
+	}
+	if err := FetchByMessage(q, errNotFoundB); err != errNotFoundB {
+		t.Errorf("FetchByMessage(%v, %v) != %v, got %v", q, errNotFoundB, errNotFoundB, err)
+	}
+	if err := FetchByMessage(q, New("missing")); err != nil {
+		t.Errorf("FetchByMessage(%v, missing) must return nil, got %v", q, err)
+	}
+}
+
 func TestErrorMatches(t *testing.T) {
 	var (
 		err1 = New("1")
@@ -600,3 +697,293 @@ func TestErrorMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapPreservesHierarchy(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		err3 = New("3")
+		q21  = Wrap(err1, err2)
+	)
+
+	q, ok := Wrap(q21, err3).(*queue)
+	if !ok {
+		t.Fatalf("Wrap(%v, %v) returned not an errors.queue instance", q21, err3)
+	}
+
+	children := q.Unwrap()
+	if len(children) != 2 || children[0] != err3 || children[1] != q21 {
+		t.Errorf("Wrap(%v, %v) must keep the wrapped queue as a child, got %v", q21, err3, children)
+	}
+	if q.Error() != "3 : 2 : 1" {
+		t.Errorf("Wrap(%v, %v) error message mismatch, %q != %q", q21, err3, "3 : 2 : 1", q.Error())
+	}
+}
+
+func TestWalk(t *testing.T) {
+	type node struct {
+		msg   string
+		depth int
+	}
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		err3 = New("3")
+		err4 = New("4")
+	)
+
+	tcs := []struct {
+		name  string
+		err   error
+		nodes []node
+	}{
+		{
+			name:  "ForANilError",
+			err:   nil,
+			nodes: nil,
+		},
+		{
+			name:  "ForAnError",
+			err:   err1,
+			nodes: []node{{"1", 0}},
+		},
+		{
+			name:  "ForAFlatQueue",
+			err:   Wrap(err1, err2),
+			nodes: []node{{"2 : 1", 0}, {"2", 1}, {"1", 1}},
+		},
+		{
+			name: "ForNestedQueues",
+			err:  Wrap(Wrap(err1, err2), Wrap(err3), err4),
+			nodes: []node{
+				{"4 : 3 : 2 : 1", 0},
+				{"4", 1},
+				{"3", 1}, {"3", 2},
+				{"2 : 1", 1}, {"2", 2}, {"1", 2},
+			},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var nodes []node
+			Walk(tc.err, func(err error, depth int) bool {
+				nodes = append(nodes, node{err.Error(), depth})
+				return true
+			})
+
+			if !reflect.DeepEqual(nodes, tc.nodes) {
+				t.Errorf("Walk(%v) visited %v, want %v", tc.err, nodes, tc.nodes)
+			}
+		})
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	var visited int
+	err := Wrap(Wrap(New("1"), New("2")), New("3"))
+
+	Walk(err, func(err error, depth int) bool {
+		visited++
+		return depth == 0
+	})
+
+	if visited != 2 {
+		t.Errorf("Walk(%v) must stop once fn returns false, visited %d nodes", err, visited)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+	)
+
+	tcs := []struct {
+		name string
+		err  error
+		msgs []string
+	}{
+		{
+			name: "ForANilError",
+			err:  nil,
+			msgs: nil,
+		},
+		{
+			name: "ForAnErrorWithoutMessages",
+			err:  Wrap(err1, err2),
+			msgs: nil,
+		},
+		{
+			name: "ForAnEmptyMessage",
+			err:  WithMessage(err1, ""),
+			msgs: nil,
+		},
+		{
+			name: "ForAMessage",
+			err:  WithMessage(err1, "user %d", 42),
+			msgs: []string{"user 42"},
+		},
+		{
+			name: "ForSeveralMessages",
+			err:  WithMessage(Wrap(WrapWithMessage(err1, err2, "inner"), New("3")), "outer"),
+			msgs: []string{"outer", "inner"},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if msgs := Messages(tc.err); !reflect.DeepEqual(msgs, tc.msgs) {
+				t.Errorf("Messages(%v) != %v, got %v", tc.err, tc.msgs, msgs)
+			}
+		})
+	}
+}
+
+func TestFetchAs(t *testing.T) {
+	err1 := New("1")
+	source := Wrap(err1, customError{"2"}, &customError{"3"}, fmt.Errorf("context: %w", customError{"4"}))
+
+	t.Run("ForAValueType", func(t *testing.T) {
+		res, ok := FetchAs[customError](source)
+		if !ok || res != (customError{"4"}) {
+			t.Errorf("FetchAs[customError](%v) != %v, got %v", source, customError{"4"}, res)
+		}
+		if all := FetchAll[customError](source); !reflect.DeepEqual(all, []customError{{"4"}, {"3"}, {"2"}}) {
+			t.Errorf("FetchAll[customError](%v) mismatch, got %v", source, all)
+		}
+	})
+
+	t.Run("ForAPointerType", func(t *testing.T) {
+		res, ok := FetchAs[*customError](source)
+		if !ok || *res != (customError{"3"}) {
+			t.Errorf("FetchAs[*customError](%v) != %v, got %v", source, &customError{"3"}, res)
+		}
+		if all := FetchAll[*customError](source); len(all) != 1 {
+			t.Errorf("FetchAll[*customError](%v) mismatch, got %v", source, all)
+		}
+	})
+
+	t.Run("ForAnInterface", func(t *testing.T) {
+		res, ok := FetchAs[customErrorInterface](source)
+		if !ok || res.Message() != "4" {
+			t.Errorf("FetchAs[customErrorInterface](%v) != %v, got %v", source, customError{"4"}, res)
+		}
+		if all := FetchAll[customErrorInterface](source); len(all) != 3 {
+			t.Errorf("FetchAll[customErrorInterface](%v) mismatch, got %v", source, all)
+		}
+	})
+
+	t.Run("ForAMissingType", func(t *testing.T) {
+		if res, ok := FetchAs[customError](err1); ok || res != (customError{}) {
+			t.Errorf("FetchAs[customError](%v) must return a zero value, got %v", err1, res)
+		}
+		if all := FetchAll[customInterface](source); all != nil {
+			t.Errorf("FetchAll[customInterface](%v) must return nil, got %v", source, all)
+		}
+	})
+
+	t.Run("ForANilError", func(t *testing.T) {
+		if _, ok := FetchAs[error](nil); ok {
+			t.Error("FetchAs[error](nil) must return false")
+		}
+		if all := FetchAll[error](nil); all != nil {
+			t.Errorf("FetchAll[error](nil) must return nil, got %v", all)
+		}
+	})
+}
+
+func TestFetchAny(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		err3 = New("3")
+	)
+
+	tcs := []struct {
+		name    string
+		source  error
+		targets []error
+		res     error
+	}{
+		{
+			name:    "ForANilError",
+			source:  nil,
+			targets: []error{err1},
+			res:     nil,
+		},
+		{
+			name:    "ForNoTargets",
+			source:  Wrap(err1),
+			targets: nil,
+			res:     nil,
+		},
+		{
+			name:    "ForANilTarget",
+			source:  Wrap(err1),
+			targets: []error{nil, err1},
+			res:     err1,
+		},
+		{
+			name:    "ForSeveralMatchedTargets",
+			source:  Wrap(err1, err2),
+			targets: []error{err1, err2},
+			res:     err2,
+		},
+		{
+			name:    "ForANestedTarget",
+			source:  WithMessage(Wrap(err1, fmt.Errorf("context: %w", err2)), "message"),
+			targets: []error{err1, err2},
+			res:     err2,
+		},
+		{
+			name:    "ForMissingTargets",
+			source:  Wrap(err1, err2),
+			targets: []error{err3, New("1")},
+			res:     nil,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if res := FetchAny(tc.source, tc.targets...); res != tc.res {
+				t.Errorf("FetchAny(%v, %v) != %v, got %v", tc.source, tc.targets, tc.res, res)
+			}
+		})
+	}
+}
+
+func TestFetchFunc(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = customError{"2"}
+		err3 = customError{"3"}
+	)
+	source := Wrap(err1, err2, WithMessage(err3, "message"))
+	isCustomError := func(err error) bool {
+		_, ok := err.(customError)
+		return ok
+	}
+
+	var visited []error
+	res := FetchFunc(source, func(err error) bool {
+		visited = append(visited, err)
+		return false
+	})
+	if res != nil || fmt.Sprint(visited) != "[message 3 2 1]" {
+		t.Errorf("FetchFunc(%v) must visit all errors in the outer-to-inner order, got %v", source, visited)
+	}
+
+	if res := FetchFunc(source, isCustomError); res != err3 {
+		t.Errorf("FetchFunc(%v) != %v, got %v", source, err3, res)
+	}
+	if res := FetchAllFunc(source, isCustomError); !reflect.DeepEqual(res, []error{err3, err2}) {
+		t.Errorf("FetchAllFunc(%v) != %v, got %v", source, []error{err3, err2}, res)
+	}
+	if res := FetchAllFunc(nil, isCustomError); res != nil {
+		t.Errorf("FetchAllFunc(nil) must return nil, got %v", res)
+	}
+}
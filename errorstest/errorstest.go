@@ -0,0 +1,146 @@
+// Package errorstest provides assertion helpers for testing error queues.
+//
+// Every assertion reports a failure with t.Errorf() and describes the contents of the inspected error, so there is no
+// need to print the error queue by hand. Assertions return true if they have succeeded, so a test can stop early:
+//
+//	if !errorstest.AssertContains(t, err, ErrNotFound) {
+//		return
+//	}
+package errorstest
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ameteiko/errors"
+)
+
+// AssertContains asserts that err contains the target error in terms of errors.Fetch().
+func AssertContains(t testing.TB, err, target error) bool {
+	t.Helper()
+
+	return Assert(t, err, IsSentinel(target))
+}
+
+// AssertContainsType asserts that err contains an error of the target type in terms of errors.FetchByType().
+// target is either a nil pointer to a struct or interface, e.g. (*ValidationError)(nil), or an error value.
+func AssertContainsType(t testing.TB, err error, target interface{}) bool {
+	t.Helper()
+
+	return Assert(t, err, HasType(target))
+}
+
+// AssertChain asserts that err contains the targets in the given outer-to-inner order.
+// The targets don't have to be adjacent, other errors may be found between them.
+func AssertChain(t testing.TB, err error, targets ...error) bool {
+	t.Helper()
+
+	members := Members(err)
+	next := 0
+	for _, member := range members {
+		if next < len(targets) && stderrors.Is(member, targets[next]) {
+			next++
+		}
+	}
+	if next == len(targets) {
+		return true
+	}
+
+	t.Errorf("error doesn't contain the chain %s, %v is missing or out of order\n%s",
+		formatErrs(targets), targets[next], Describe(err))
+
+	return false
+}
+
+// AssertMessage asserts that the error message is msg.
+func AssertMessage(t testing.TB, err error, msg string) bool {
+	t.Helper()
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if err != nil && errMsg == msg {
+		return true
+	}
+
+	t.Errorf("error message mismatch\nwant: %q\ngot:  %q\n%s", msg, errMsg, Describe(err))
+
+	return false
+}
+
+// Assert asserts that err matches all matchers.
+func Assert(t testing.TB, err error, matchers ...Matcher) bool {
+	t.Helper()
+
+	m := AllOf(matchers...)
+	if m.Matches(err) {
+		return true
+	}
+
+	t.Errorf("error doesn't match: %s\n%s", m, Describe(err))
+
+	return false
+}
+
+// Members returns the errors contained in err in the outer-to-inner order, the error queues are expanded.
+func Members(err error) []error {
+	return errors.FetchAllByType(err, (*error)(nil))
+}
+
+// Describe returns a human readable description of err: the wrapping tree with the messages and Go types of all nodes
+// and the fields attached to the error.
+func Describe(err error) string {
+	if err == nil {
+		return "error: <nil>"
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("error:")
+	errors.Walk(err, func(err error, depth int) bool {
+		fmt.Fprintf(buf, "\n%s%q (%T)", strings.Repeat("  ", depth+1), err.Error(), err)
+		return true
+	})
+
+	fields := errors.Fields(err)
+	if len(fields) == 0 {
+		return buf.String()
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf.WriteString("\nfields:")
+	for _, k := range keys {
+		fmt.Fprintf(buf, "\n  %s: %#v", k, fields[k])
+	}
+
+	return buf.String()
+}
+
+// formatErrs returns the messages of errs in form of [err1, err2].
+func formatErrs(errs []error) string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = fmt.Sprintf("%q", err)
+	}
+
+	return "[" + strings.Join(msgs, ", ") + "]"
+}
+
+// typeName returns the name of the type the target describes.
+func typeName(target interface{}) string {
+	t := reflect.TypeOf(target)
+	if t != nil && t.Kind() == reflect.Ptr && reflect.ValueOf(target).IsNil() {
+		return t.Elem().String()
+	}
+
+	return fmt.Sprintf("%T", target)
+}
@@ -0,0 +1,181 @@
+package errorstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ameteiko/errors"
+)
+
+var (
+	err1 = errors.New("1")
+	err2 = errors.New("2")
+	err3 = errors.New("3")
+)
+
+type validationError struct{ field string }
+
+func (e validationError) Error() string { return e.field + " is invalid" }
+
+// recorder is a testing.TB recording failures.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	err := errors.WithFields(errors.WrapWithMessage(err1, validationError{field: "name"}, "validation"), "id", 42)
+
+	tcs := []struct {
+		name    string
+		assert  func(t testing.TB) bool
+		failure string
+	}{
+		{
+			name:   "AssertContainsForAContainedError",
+			assert: func(t testing.TB) bool { return AssertContains(t, err, err1) },
+		},
+		{
+			name:    "AssertContainsForAMissingError",
+			assert:  func(t testing.TB) bool { return AssertContains(t, err, err2) },
+			failure: `error doesn't match: contains "2"`,
+		},
+		{
+			name:   "AssertContainsTypeForAContainedType",
+			assert: func(t testing.TB) bool { return AssertContainsType(t, err, (*validationError)(nil)) },
+		},
+		{
+			name:    "AssertContainsTypeForAMissingType",
+			assert:  func(t testing.TB) bool { return AssertContainsType(t, err1, (*validationError)(nil)) },
+			failure: "error doesn't match: contains an error of type errorstest.validationError",
+		},
+		{
+			name:   "AssertChainForAnOrderedChain",
+			assert: func(t testing.TB) bool { return AssertChain(t, errors.Wrap(err1, err2, err3), err3, err1) },
+		},
+		{
+			name:    "AssertChainForAnUnorderedChain",
+			assert:  func(t testing.TB) bool { return AssertChain(t, errors.Wrap(err1, err2, err3), err1, err3) },
+			failure: `error doesn't contain the chain ["1", "3"], 3 is missing or out of order`,
+		},
+		{
+			name:   "AssertMessageForAMatchedMessage",
+			assert: func(t testing.TB) bool { return AssertMessage(t, err, "validation : name is invalid : 1") },
+		},
+		{
+			name:    "AssertMessageForANilError",
+			assert:  func(t testing.TB) bool { return AssertMessage(t, nil, "") },
+			failure: "error message mismatch\nwant: \"\"\ngot:  \"\"\nerror: <nil>",
+		},
+		{
+			name:   "AssertForMatchers",
+			assert: func(t testing.TB) bool { return Assert(t, err, IsSentinel(err1), HasField("id", 42)) },
+		},
+		{
+			name:    "AssertForAFailedMatcher",
+			assert:  func(t testing.TB) bool { return Assert(t, err, IsSentinel(err1), HasField("id", "42")) },
+			failure: `error doesn't match: (contains "1") and (has field id: "42")`,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			ok := tc.assert(r)
+
+			if ok != (tc.failure == "") {
+				t.Fatalf("assertion must return %t, failures %q", tc.failure == "", r.failures)
+			}
+			if tc.failure == "" && len(r.failures) != 0 {
+				t.Errorf("assertion mustn't fail, got %q", r.failures)
+			}
+			if tc.failure != "" && (len(r.failures) != 1 || !strings.HasPrefix(r.failures[0], tc.failure)) {
+				t.Errorf("assertion must fail with %q, got %q", tc.failure, r.failures)
+			}
+		})
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	err := errors.WithFields(errors.Wrap(err1, err2), "id", 42)
+
+	tcs := []struct {
+		name    string
+		matcher Matcher
+		matches bool
+		desc    string
+	}{
+		{
+			name:    "IsSentinel",
+			matcher: IsSentinel(err1),
+			matches: true,
+			desc:    `contains "1"`,
+		},
+		{
+			name:    "HasType",
+			matcher: HasType((*validationError)(nil)),
+			matches: false,
+			desc:    "contains an error of type errorstest.validationError",
+		},
+		{
+			name:    "HasField",
+			matcher: HasField("id", 42),
+			matches: true,
+			desc:    "has field id: 42",
+		},
+		{
+			name:    "HasFieldForAMissingField",
+			matcher: HasField("user", nil),
+			matches: false,
+			desc:    "has field user: <nil>",
+		},
+		{
+			name:    "AnyOf",
+			matcher: AnyOf(IsSentinel(err3), IsSentinel(err2)),
+			matches: true,
+			desc:    `(contains "3") or (contains "2")`,
+		},
+		{
+			name:    "Not",
+			matcher: Not(IsSentinel(err3)),
+			matches: true,
+			desc:    `not (contains "3")`,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if matches := tc.matcher.Matches(err); matches != tc.matches {
+				t.Errorf("Matches(%v) != %t", err, tc.matches)
+			}
+			if desc := tc.matcher.String(); desc != tc.desc {
+				t.Errorf("String() != %q, got %q", tc.desc, desc)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	err := errors.WithFields(errors.WithMessage(err1, "message"), "id", 42, "name", "bob")
+
+	expected := `error:
+  "message : 1" (*errors.queue)
+    "message : 1" (*errors.queue)
+      "message" (*errors.errorString)
+      "1" (*errors.errorString)
+fields:
+  id: 42
+  name: "bob"`
+	if desc := Describe(err); desc != expected {
+		t.Errorf("Describe() mismatch\nwant: %s\ngot:  %s", expected, desc)
+	}
+}
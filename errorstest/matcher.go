@@ -0,0 +1,117 @@
+package errorstest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ameteiko/errors"
+)
+
+// Matcher describes an expectation about an error.
+type Matcher interface {
+	// Matches returns true if the error meets the expectation.
+	Matches(err error) bool
+	// String describes the expectation.
+	String() string
+}
+
+// matcher is a Matcher implemented with a function.
+type matcher struct {
+	desc    string
+	matches func(err error) bool
+}
+
+// Matches returns true if the error meets the expectation.
+func (m matcher) Matches(err error) bool { return m.matches(err) }
+
+// String describes the expectation.
+func (m matcher) String() string { return m.desc }
+
+// IsSentinel returns a matcher for errors containing the target error in terms of errors.Fetch().
+func IsSentinel(target error) Matcher {
+	return matcher{
+		desc: fmt.Sprintf("contains %q", target),
+		matches: func(err error) bool {
+			return errors.Fetch(err, target) != nil
+		},
+	}
+}
+
+// HasType returns a matcher for errors containing an error of the target type in terms of errors.FetchByType().
+func HasType(target interface{}) Matcher {
+	return matcher{
+		desc: "contains an error of type " + typeName(target),
+		matches: func(err error) bool {
+			return errors.FetchByType(err, target) != nil
+		},
+	}
+}
+
+// HasField returns a matcher for errors having the field with the value in terms of errors.Fields().
+// The values are compared with reflect.DeepEqual().
+func HasField(key string, value interface{}) Matcher {
+	return matcher{
+		desc: fmt.Sprintf("has field %s: %#v", key, value),
+		matches: func(err error) bool {
+			v, ok := errors.Fields(err)[key]
+			return ok && reflect.DeepEqual(v, value)
+		},
+	}
+}
+
+// AllOf returns a matcher for errors matching all matchers.
+func AllOf(matchers ...Matcher) Matcher {
+	if len(matchers) == 1 {
+		return matchers[0]
+	}
+
+	return matcher{
+		desc: joinMatchers(matchers, " and "),
+		matches: func(err error) bool {
+			for _, m := range matchers {
+				if !m.Matches(err) {
+					return false
+				}
+			}
+
+			return true
+		},
+	}
+}
+
+// AnyOf returns a matcher for errors matching at least one of matchers.
+func AnyOf(matchers ...Matcher) Matcher {
+	return matcher{
+		desc: joinMatchers(matchers, " or "),
+		matches: func(err error) bool {
+			for _, m := range matchers {
+				if m.Matches(err) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// Not returns a matcher for errors not matching m.
+func Not(m Matcher) Matcher {
+	return matcher{
+		desc: "not (" + m.String() + ")",
+		matches: func(err error) bool {
+			return !m.Matches(err)
+		},
+	}
+}
+
+// joinMatchers returns the descriptions of matchers joined with sep.
+func joinMatchers(matchers []Matcher, sep string) string {
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = "(" + m.String() + ")"
+	}
+
+	return strings.Join(descs, sep)
+}
@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// ErrAccountLocked is a coded application error.
+var ErrAccountLocked = NewCoded("account_locked", "account is locked")
+
+func login(user string) error {
+	return WithMessage(ErrAccountLocked, "login failed for %q", user)
+}
+
+func ExampleCodeOf() {
+	err := login("bob")
+	fmt.Println(err)
+
+	switch CodeOf(err) {
+	case "account_locked":
+		fmt.Println("ask the user to contact the support")
+	default:
+		fmt.Println("try again later")
+	}
+
+	// Output:
+	// login failed for "bob" : account is locked
+	// ask the user to contact the support
+}
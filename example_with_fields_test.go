@@ -0,0 +1,25 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUserNotFound is a named application error.
+var ErrUserNotFound = errors.New("user not found")
+
+func findUser(id int) error {
+	return WithFields(ErrUserNotFound, "user_id", id)
+}
+
+func ExampleWithFields() {
+	err := WithFields(WithMessage(findUser(42), "profile is unavailable"), "request_id", "7f3a")
+	fmt.Println(err)
+
+	fields := Fields(err)
+	fmt.Println(fields["user_id"], fields["request_id"])
+
+	// Output:
+	// profile is unavailable : user not found
+	// 42 7f3a
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// fielder is implemented by errors that expose their data as a set of named fields.
+type fielder interface {
+	Fields() map[string]interface{}
+}
+
+// WithFields returns an error with attached key/value fields.
+// keyvals is a list of alternating keys and values, e.g. WithFields(err, "user_id", 42, "request_id", reqID). Keys
+// that are not strings are converted with fmt.Sprint(), a key without a value gets a nil one. Fields are inspectable
+// with Fields() and don't affect the error message.
+func WithFields(err error, keyvals ...interface{}) error {
+	if isErrNil(err) {
+		return nil
+	}
+
+	q := newQueue(err)
+	q.fields = make(map[string]interface{}, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+
+		var val interface{}
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+		q.fields[key] = val
+	}
+
+	return q
+}
+
+// Fields returns all fields attached to the error.
+// Fields of all wrapped queues are merged, the outer ones take precedence over the inner ones with the same key. Errors
+// having a Fields() map[string]interface{} method contribute their fields too. Returns nil if there are no fields.
+func Fields(err error) map[string]interface{} {
+	var fields map[string]interface{}
+	Walk(err, func(err error, _ int) bool {
+		var errFields map[string]interface{}
+		if q, ok := err.(*queue); ok {
+			errFields = q.fields
+		} else if f, ok := err.(fielder); ok {
+			errFields = f.Fields()
+		}
+
+		for k, v := range errFields {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			if _, ok := fields[k]; !ok {
+				fields[k] = v
+			}
+		}
+
+		return true
+	})
+
+	return fields
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	err1 := New("1")
+
+	tcs := []struct {
+		name    string
+		err     error
+		keyvals []interface{}
+		fields  map[string]interface{}
+	}{
+		{
+			name:    "ForANilError",
+			err:     nil,
+			keyvals: []interface{}{"key", "value"},
+			fields:  nil,
+		},
+		{
+			name:    "ForNoFields",
+			err:     err1,
+			keyvals: nil,
+			fields:  nil,
+		},
+		{
+			name:    "ForSeveralFields",
+			err:     err1,
+			keyvals: []interface{}{"user_id", 42, "request_id", "abc"},
+			fields:  map[string]interface{}{"user_id": 42, "request_id": "abc"},
+		},
+		{
+			name:    "ForANotStringKey",
+			err:     err1,
+			keyvals: []interface{}{1, "one"},
+			fields:  map[string]interface{}{"1": "one"},
+		},
+		{
+			name:    "ForAKeyWithoutAValue",
+			err:     err1,
+			keyvals: []interface{}{"key"},
+			fields:  map[string]interface{}{"key": nil},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := WithFields(tc.err, tc.keyvals...)
+			if tc.err == nil && err != nil {
+				t.Fatalf("WithFields(nil, %v) must return nil, got %v", tc.keyvals, err)
+			}
+			if tc.err != nil && err.Error() != tc.err.Error() {
+				t.Errorf("WithFields(%v, %v) mustn't change the error message, got %q", tc.err, tc.keyvals, err)
+			}
+
+			if fields := Fields(err); !reflect.DeepEqual(fields, tc.fields) {
+				t.Errorf("Fields(WithFields(%v, %v)) != %v, got %v", tc.err, tc.keyvals, tc.fields, fields)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	var (
+		err1  = New("1")
+		err2  = New("2")
+		inner = WithFields(err1, "id", 1, "inner", true)
+		other = WithFields(err2, "other", true)
+	)
+
+	tcs := []struct {
+		name   string
+		err    error
+		fields map[string]interface{}
+	}{
+		{
+			name:   "ForANilError",
+			err:    nil,
+			fields: nil,
+		},
+		{
+			name:   "ForAnErrorWithoutFields",
+			err:    Wrap(err1, err2),
+			fields: nil,
+		},
+		{
+			name:   "ForAWrappedQueue",
+			err:    Wrap(inner, err2),
+			fields: map[string]interface{}{"id": 1, "inner": true},
+		},
+		{
+			name:   "ForSeveralWrappedQueues",
+			err:    Wrap(inner, other),
+			fields: map[string]interface{}{"id": 1, "inner": true, "other": true},
+		},
+		{
+			name:   "ForOverriddenFields",
+			err:    WithFields(WithMessage(inner, "message"), "id", 2),
+			fields: map[string]interface{}{"id": 2, "inner": true},
+		},
+		{
+			name:   "ForAnErrorProvidingFields",
+			err:    Wrap(fieldsError{id: 3}, inner),
+			fields: map[string]interface{}{"id": 1, "inner": true},
+		},
+		{
+			name:   "ForAQueueWrappedByTheStdlib",
+			err:    fmt.Errorf("context: %w", inner),
+			fields: map[string]interface{}{"id": 1, "inner": true},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if fields := Fields(tc.err); !reflect.DeepEqual(fields, tc.fields) {
+				t.Errorf("Fields(%v) != %v, got %v", tc.err, tc.fields, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// nolint:gochecknoglobals
+var frameFilter atomic.Pointer[FrameFilter]
+
+// FrameFilter defines which stacktrace frames are rendered.
+//
+// Patterns match either the frame package or the fully qualified frame function. A pattern is a path.Match() one,
+// e.g. "net/http" or "github.com/acme/app.(*Server).*", and a pattern ending with "/..." matches the package and all
+// its subpackages, e.g. "github.com/acme/app/...".
+type FrameFilter struct {
+	Include []string           // Patterns of the frames to render, all frames are rendered if empty.
+	Exclude []string           // Patterns of the frames to hide.
+	Stop    func(f Frame) bool // Nothing is rendered beyond the frame Stop returns true for, StopAtMain if nil.
+	Compact bool               // Collapses runs of hidden frames into a single "... N frames hidden" line.
+}
+
+// StopAtMain returns true for the main.main() function frame.
+// It is the default FrameFilter.Stop predicate.
+func StopAtMain(f Frame) bool {
+	return f.Function == "main.main"
+}
+
+// SetFrameFilter sets the filter for the rendered stacktraces.
+// It affects the %+v output, JSON and log representations of errors and Stacktrace.Frames(). It is safe to change the
+// filter at runtime.
+func SetFrameFilter(f FrameFilter) {
+	f.Include = append([]string(nil), f.Include...)
+	f.Exclude = append([]string(nil), f.Exclude...)
+	frameFilter.Store(&f)
+}
+
+// CurrentFrameFilter returns the current frame filter.
+func CurrentFrameFilter() FrameFilter {
+	if f := frameFilter.Load(); f != nil {
+		return *f
+	}
+
+	return FrameFilter{}
+}
+
+// Matches returns true if the frame is to be rendered.
+func (ff FrameFilter) Matches(f Frame) bool {
+	if len(ff.Include) > 0 && !matchesAnyPattern(f, ff.Include) {
+		return false
+	}
+
+	return !matchesAnyPattern(f, ff.Exclude)
+}
+
+// Filter returns the frames to be rendered.
+func (ff FrameFilter) Filter(frames []Frame) []Frame {
+	filtered := frames[:0:0]
+	for _, f := range frames {
+		if ff.Matches(f) {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered
+}
+
+// stop returns the stop predicate of the filter.
+func (ff FrameFilter) stop() func(f Frame) bool {
+	if ff.Stop == nil {
+		return StopAtMain
+	}
+
+	return ff.Stop
+}
+
+// lines returns the text representation of the frames to be rendered.
+func (ff FrameFilter) lines(frames []Frame) []string {
+	var (
+		lines  = make([]string, 0, len(frames))
+		hidden int
+	)
+	flushHidden := func() {
+		if ff.Compact && hidden > 0 {
+			lines = append(lines, "... "+strconv.Itoa(hidden)+" frames hidden")
+		}
+		hidden = 0
+	}
+
+	for _, f := range frames {
+		if !ff.Matches(f) {
+			hidden++
+			continue
+		}
+
+		flushHidden()
+		lines = append(lines, formatFrame(f))
+	}
+	flushHidden()
+
+	return lines
+}
+
+// matchesAnyPattern returns true if the frame matches any of the patterns.
+func matchesAnyPattern(f Frame, patterns []string) bool {
+	for _, p := range patterns {
+		if matchesPattern(f, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPattern returns true if the frame package or function matches the pattern.
+func matchesPattern(f Frame, pattern string) bool {
+	if pkg := strings.TrimSuffix(pattern, "/..."); pkg != pattern {
+		return f.Package == pkg || strings.HasPrefix(f.Package, pkg+"/")
+	}
+
+	if matched, _ := path.Match(pattern, f.Package); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, f.Function)
+
+	return matched
+}
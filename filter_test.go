@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setFrameFilter sets the frame filter for the test duration.
+func setFrameFilter(tb testing.TB, f FrameFilter) {
+	tb.Helper()
+
+	prev := CurrentFrameFilter()
+	SetFrameFilter(f)
+	tb.Cleanup(func() { SetFrameFilter(prev) })
+}
+
+func TestMatchesPattern(t *testing.T) {
+	f := Frame{Function: "github.com/acme/app/api.(*Server).ServeHTTP", Package: "github.com/acme/app/api"}
+
+	tcs := []struct {
+		desc    string
+		pattern string
+		matched bool
+	}{
+		{`for the package`, "github.com/acme/app/api", true},
+		{`for another package`, "github.com/acme/app", false},
+		{`for a package wildcard`, "github.com/acme/*/api", true},
+		{`for the parent package with subpackages`, "github.com/acme/app/...", true},
+		{`for the package with subpackages`, "github.com/acme/app/api/...", true},
+		{`for a package prefix with subpackages`, "github.com/acme/ap/...", false},
+		{`for the function`, "github.com/acme/app/api.(*Server).ServeHTTP", true},
+		{`for a function wildcard`, "github.com/acme/app/api.*", true},
+		{`for another function`, "github.com/acme/app/api.Handle", false},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if matched := matchesPattern(f, tc.pattern); matched != tc.matched {
+				t.Errorf("matchesPattern(%v, %q)=%v, got %v", f, tc.pattern, tc.matched, matched)
+			}
+		})
+	}
+}
+
+func TestFrameFilterLines(t *testing.T) {
+	frames := []Frame{
+		{File: "/app/api/handler.go", Line: 10, Function: "app/api.handle", Package: "app/api"},
+		{File: "/app/mw/auth.go", Line: 20, Function: "app/mw.auth.func1", Package: "app/mw"},
+		{File: "/app/mw/log.go", Line: 30, Function: "app/mw.log.func1", Package: "app/mw"},
+		{File: goroot + "net/http/server.go", Line: 40, Function: "net/http.HandlerFunc.ServeHTTP", Package: "net/http"},
+		{File: "/app/main.go", Line: 50, Function: "main.main", Package: "main"},
+	}
+
+	tcs := []struct {
+		desc   string
+		filter FrameFilter
+		lines  []string
+	}{
+		{
+			`for an empty filter`,
+			FrameFilter{},
+			[]string{
+				"app/api/handler.go:10 api.handle()",
+				"app/mw/auth.go:20 mw.auth.func1()",
+				"app/mw/log.go:30 mw.log.func1()",
+				"net/http/server.go:40 http.HandlerFunc.ServeHTTP()",
+				"/app/main.go:50 main.main()",
+			},
+		},
+		{
+			`for excluded frames`,
+			FrameFilter{Exclude: []string{"app/mw", "net/http"}},
+			[]string{"app/api/handler.go:10 api.handle()", "/app/main.go:50 main.main()"},
+		},
+		{
+			`for included frames`,
+			FrameFilter{Include: []string{"app/...", "main"}, Exclude: []string{"app/mw.log.*"}},
+			[]string{
+				"app/api/handler.go:10 api.handle()",
+				"app/mw/auth.go:20 mw.auth.func1()",
+				"/app/main.go:50 main.main()",
+			},
+		},
+		{
+			`for the compact mode`,
+			FrameFilter{Exclude: []string{"app/mw", "net/http", "main"}, Compact: true},
+			[]string{"app/api/handler.go:10 api.handle()", "... 4 frames hidden"},
+		},
+		{
+			`for the compact mode with several runs of hidden frames`,
+			FrameFilter{Exclude: []string{"app/mw.log.*", "net/http"}, Compact: true},
+			[]string{
+				"app/api/handler.go:10 api.handle()",
+				"app/mw/auth.go:20 mw.auth.func1()",
+				"... 2 frames hidden",
+				"/app/main.go:50 main.main()",
+			},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if lines := tc.filter.lines(frames); !reflect.DeepEqual(lines, tc.lines) {
+				t.Errorf("lines() for %+v\n%q != \n%q", tc.filter, tc.lines, lines)
+			}
+		})
+	}
+}
+
+func TestStopAtMain(t *testing.T) {
+	if !StopAtMain(Frame{Function: "main.main", Package: "main"}) {
+		t.Errorf("StopAtMain() must return true for main.main()")
+	}
+	if StopAtMain(Frame{Function: "main.run", Package: "main"}) {
+		t.Errorf("StopAtMain() must return false for main.run()")
+	}
+}
+
+func TestFramesForAStopPredicate(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+	setFrameFilter(t, FrameFilter{Stop: func(f Frame) bool { return f.Function == "testing.tRunner" }})
+
+	frames := StackTrace(Wrap(New("1"))).Frames()
+
+	if len(frames) == 0 || frames[len(frames)-1].Function != "testing.tRunner" {
+		t.Errorf("Frames() must stop at testing.tRunner(), got %v", frames)
+	}
+}
+
+func TestFormatForAFrameFilter(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+	setFrameFilter(t, FrameFilter{Exclude: []string{"runtime", "testing"}, Compact: true})
+
+	output := fmt.Sprintf("%+v", Wrap(New("1")))
+
+	if strings.Contains(output, "testing.tRunner") || strings.Contains(output, "runtime.goexit") {
+		t.Errorf("%%+v mustn't render excluded frames, got %q", output)
+	}
+	if !strings.Contains(output, "TestFormatForAFrameFilter()") || !strings.HasSuffix(output, "\t... 2 frames hidden\n") {
+		t.Errorf("%%+v must render the included frames and the hidden frames summary, got %q", output)
+	}
+}
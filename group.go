@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions in goroutines and collects their errors into a single error queue.
+//
+// Unlike several goroutines wrapping errors into a shared variable with Wrap(), the group keeps the error of every
+// goroutine as a separate branch of the resulting error together with its stacktrace. Panics are recovered with
+// Recover(). A zero Group has no concurrency limit, doesn't fail fast and doesn't cancel a context.
+type Group struct {
+	cancel   context.CancelCauseFunc
+	ctx      context.Context
+	failFast bool
+	sem      chan struct{}
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	failed bool    // Set once a function returned an error.
+	errs   []error // Errors of the functions in the order of Go() calls, nil for succeeded ones.
+}
+
+// NewGroup returns a new group and a context derived from ctx.
+// The context is cancelled once Wait() returns or, in the fail-fast mode, as soon as a function returns an error.
+// In the latter case the error is the context cause.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return &Group{cancel: cancel, ctx: ctx}, ctx
+}
+
+// SetLimit limits the number of active goroutines to n, a non-positive n removes the limit.
+// Go() blocks until the number of active goroutines is below the limit. The limit mustn't be changed while there are
+// active goroutines.
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// SetFailFast enables or disables the fail-fast mode.
+// In the fail-fast mode the first error cancels the group context and the functions that are not started yet are
+// skipped. The functions are skipped as well if the parent context is done.
+func (g *Group) SetFailFast(failFast bool) {
+	g.failFast = failFast
+}
+
+// Go calls fn in a new goroutine.
+// If fn returns an error, it is wrapped into a branch with the stacktrace of the Go() call, unless the error carries
+// its own one. A panic in fn is converted into an error by Recover().
+func (g *Group) Go(fn func() error) {
+	q := newQueue()
+
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	i := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+
+		if g.skip() {
+			return
+		}
+		if err := wrap(q, run(fn)); err != nil {
+			g.setErr(i, err)
+		}
+	}()
+}
+
+// Wait blocks until all functions return and returns an error wrapping the errors they have returned.
+// The errors are children of the returned error in the order of Go() calls. The returned error keeps the stacktraces of
+// all branches, see StackPolicyKeepAll. Returns nil if all functions have succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	q := newQueue()
+	q.stackPolicy = StackPolicyKeepAll
+	for i := len(g.errs) - 1; i >= 0; i-- {
+		if g.errs[i] != nil {
+			q.errs = append(q.errs, g.errs[i])
+		}
+	}
+	if len(q.errs) == 0 {
+		return nil
+	}
+
+	return q
+}
+
+// done marks the goroutine as finished.
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// skip returns true if the function mustn't be started because of the fail-fast mode.
+func (g *Group) skip() bool {
+	if !g.failFast {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.failed || g.ctx != nil && g.ctx.Err() != nil
+}
+
+// setErr stores the error of the i-th function and cancels the group in the fail-fast mode.
+func (g *Group) setErr(i int, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.errs[i] = err
+	if g.failFast && !g.failed {
+		g.failed = true
+		if g.cancel != nil {
+			g.cancel(err)
+		}
+	}
+}
+
+// run calls fn converting a panic into an error.
+func run(fn func() error) (err error) {
+	defer Recover(&err)
+
+	return fn()
+}
@@ -0,0 +1,203 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupWait(t *testing.T) {
+	err1, err2 := New("1"), New("2")
+
+	tcs := []struct {
+		name     string
+		fns      []func() error
+		msg      string
+		errs     []error
+		branches int
+	}{
+		{
+			name: "ForNoFunctions",
+			fns:  nil,
+			msg:  "",
+			errs: nil,
+		},
+		{
+			name: "ForSucceededFunctions",
+			fns:  []func() error{func() error { return nil }, func() error { return nil }},
+			msg:  "",
+			errs: nil,
+		},
+		{
+			name:     "ForAFailedFunction",
+			fns:      []func() error{func() error { return nil }, func() error { return err1 }},
+			msg:      "1",
+			errs:     []error{err1},
+			branches: 1,
+		},
+		{
+			name: "ForSeveralFailedFunctions",
+			fns: []func() error{
+				func() error { time.Sleep(10 * time.Millisecond); return err1 },
+				func() error { return nil },
+				func() error { return Wrap(err2) },
+			},
+			msg:      "1 : 2",
+			errs:     []error{err1, err2},
+			branches: 2,
+		},
+		{
+			name:     "ForAPanickedFunction",
+			fns:      []func() error{func() error { return err1 }, func() error { panic("boom") }},
+			msg:      "1 : panic: boom",
+			errs:     []error{err1},
+			branches: 2,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			g := new(Group)
+			for _, fn := range tc.fns {
+				g.Go(fn)
+			}
+			err := g.Wait()
+
+			if tc.msg == "" {
+				if err != nil {
+					t.Fatalf("Wait() must return nil, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.msg {
+				t.Fatalf("Wait() error message mismatch, %q != %v", tc.msg, err)
+			}
+			for _, targetErr := range tc.errs {
+				if Fetch(err, targetErr) != targetErr {
+					t.Errorf("Wait() must return an error containing %v, got %v", targetErr, err)
+				}
+			}
+			if branches := err.(interface{ Unwrap() []error }).Unwrap(); len(branches) != tc.branches {
+				t.Errorf("Wait() must return an error with a branch per failed function, got %v", branches)
+			}
+		})
+	}
+}
+
+func TestGroupKeepsTheStacktraceOfEveryBranch(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	g := new(Group)
+	g.Go(func() error { return New("1") })
+	g.Go(func() error { panic("boom") })
+	err := g.Wait()
+
+	output := fmt.Sprintf("%+v", err)
+	if !strings.Contains(output, "\n1:\n\t") || !strings.Contains(output, "\npanic: boom:\n\t") {
+		t.Errorf("%%+v must print the stacktrace of every branch, got %q", output)
+	}
+	if !strings.Contains(output, "errors.TestGroupKeepsTheStacktraceOfEveryBranch.func2()") {
+		t.Errorf("%%+v must print the stacktrace at the moment of panic, got %q", output)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	const limit = 2
+
+	var active, maxActive int32
+	g := new(Group)
+	g.SetLimit(limit)
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			for m := atomic.LoadInt32(&maxActive); n > m; m = atomic.LoadInt32(&maxActive) {
+				if atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() must return nil, got %v", err)
+	}
+	if maxActive > limit {
+		t.Errorf("Group must run at most %d goroutines, got %d", limit, maxActive)
+	}
+}
+
+func TestGroupFailFast(t *testing.T) {
+	err1 := New("1")
+
+	var started int32
+	g, ctx := NewGroup(context.Background())
+	g.SetLimit(1)
+	g.SetFailFast(true)
+	g.Go(func() error { return err1 })
+	for i := 0; i < 5; i++ {
+		g.Go(func() error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		})
+	}
+	err := g.Wait()
+
+	if err == nil || err.Error() != "1" {
+		t.Fatalf("Wait() must return the first error only, got %v", err)
+	}
+	if started != 0 {
+		t.Errorf("Group mustn't start functions after a failure in the fail-fast mode, started %d", started)
+	}
+	if cause := context.Cause(ctx); Fetch(cause, err1) != err1 {
+		t.Errorf("the group context must be cancelled with the error, got %v", cause)
+	}
+}
+
+func TestGroupFailFastForATypedNilError(t *testing.T) {
+	var started int32
+	g, ctx := NewGroup(context.Background())
+	g.SetLimit(1)
+	g.SetFailFast(true)
+	g.Go(func() error {
+		var err *customError
+		return err
+	})
+	g.Go(func() error {
+		atomic.AddInt32(&started, 1)
+		return nil
+	})
+
+	if err := ctx.Err(); err != nil {
+		t.Errorf("a typed nil error mustn't cancel the group context, got %v", err)
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() must return nil for a typed nil error, got %v", err)
+	}
+	if started != 1 {
+		t.Errorf("a typed nil error mustn't stop the fail-fast group, started %d", started)
+	}
+}
+
+func TestGroupContext(t *testing.T) {
+	g, ctx := NewGroup(context.Background())
+	g.Go(func() error { return New("1") })
+	g.Go(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return ctx.Err()
+	})
+
+	if err := g.Wait(); err == nil || err.Error() != "1" {
+		t.Errorf("the group context mustn't be cancelled before Wait() without the fail-fast mode, got %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("the group context must be cancelled once Wait() returns")
+	}
+}
@@ -0,0 +1,149 @@
+// Package httperrors maps error queues to HTTP responses.
+//
+// HTTP handlers are supposed to return errors instead of writing error responses on their own. The Registry maps
+// sentinels, error types and error codes found in the returned error to HTTP status codes and the Renderer writes the
+// response. For example, an error wrapped deep in a service with errors.Wrap(err, ErrNotFound) becomes a 404 response
+// once ErrNotFound is registered with http.StatusNotFound.
+package httperrors
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/ameteiko/errors"
+)
+
+// DefaultRegistry is the Registry used by HandlerFunc and by Handler without a registry.
+// nolint:gochecknoglobals
+var DefaultRegistry = NewRegistry()
+
+// HandlerFunc is an HTTP handler that returns an error.
+// The handler mustn't write the response if it returns an error.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and writes an error response with the DefaultRegistry and the DefaultRenderer if f fails.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Handler{Func: f}.ServeHTTP(w, r)
+}
+
+// Renderer writes an error response with the status code.
+type Renderer func(w http.ResponseWriter, r *http.Request, err error, status int)
+
+// DefaultRenderer writes the status text as a plain text response.
+// The error itself isn't exposed to clients.
+func DefaultRenderer(w http.ResponseWriter, _ *http.Request, _ error, status int) {
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Handler adapts a HandlerFunc to the http.Handler interface.
+type Handler struct {
+	Func     HandlerFunc // The handler to call.
+	Registry *Registry   // Maps errors to status codes, DefaultRegistry is used if nil.
+	Render   Renderer    // Writes error responses, DefaultRenderer is used if nil.
+}
+
+// ServeHTTP calls the handler function and writes an error response if it fails.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h.Func(w, r)
+	if err == nil {
+		return
+	}
+
+	registry := h.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	render := h.Render
+	if render == nil {
+		render = DefaultRenderer
+	}
+
+	render(w, r, err, registry.Status(err))
+}
+
+// Registry maps errors to HTTP status codes.
+// Rules are checked in the registration order, the first matched one determines the status. Registry is safe for
+// concurrent use.
+type Registry struct {
+	mu            sync.RWMutex
+	rules         []rule
+	defaultStatus int
+}
+
+// rule maps errors matched by the function to the status code.
+type rule struct {
+	matches func(err error) bool
+	status  int
+	err     error // The registered sentinel, if any.
+}
+
+// NewRegistry returns a new Registry that maps unknown errors to http.StatusInternalServerError.
+func NewRegistry() *Registry {
+	return &Registry{defaultStatus: http.StatusInternalServerError}
+}
+
+// SetDefaultStatus sets the status code for the errors that don't match any of the rules.
+func (r *Registry) SetDefaultStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaultStatus = status
+}
+
+// RegisterError maps the errors that contain targetErr in terms of errors.Fetch() to the status code.
+func (r *Registry) RegisterError(targetErr error, status int) {
+	matches := func(err error) bool { return errors.Fetch(err, targetErr) != nil }
+	r.register(rule{matches: matches, status: status, err: targetErr})
+}
+
+// RegisterType maps the errors that contain an error of the target type in terms of errors.FetchByType() to the
+// status code.
+func (r *Registry) RegisterType(target interface{}, status int) {
+	r.register(rule{matches: func(err error) bool { return errors.FetchByType(err, target) != nil }, status: status})
+}
+
+// RegisterCode maps the errors which outermost code in terms of errors.CodeOf() is code to the status code.
+func (r *Registry) RegisterCode(code errors.Code, status int) {
+	r.register(rule{matches: func(err error) bool { return errors.CodeOf(err) == code }, status: status})
+}
+
+// Status returns the status code for the error.
+// Returns http.StatusOK for a nil error.
+func (r *Registry) Status(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rl := range r.rules {
+		if rl.matches(err) {
+			return rl.status
+		}
+	}
+
+	return r.defaultStatus
+}
+
+// Errors returns the registered sentinels in the registration order.
+func (r *Registry) Errors() []error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, rl := range r.rules {
+		if rl.err != nil {
+			errs = append(errs, rl.err)
+		}
+	}
+
+	return errs
+}
+
+func (r *Registry) register(rl rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = append(r.rules, rl)
+}
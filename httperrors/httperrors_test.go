@@ -0,0 +1,177 @@
+package httperrors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ameteiko/errors"
+)
+
+var (
+	errNotFound  = errors.New("not found")
+	errForbidden = errors.NewCoded("forbidden", "forbidden")
+	errGone      = errors.NewCoded("gone", "resource is gone")
+)
+
+type validationError struct{ field string }
+
+func (e validationError) Error() string { return e.field + " is invalid" }
+
+func newTestRegistry() *Registry {
+	r := NewRegistry()
+	r.RegisterError(errNotFound, http.StatusNotFound)
+	r.RegisterType((*validationError)(nil), http.StatusBadRequest)
+	r.RegisterCode("forbidden", http.StatusForbidden)
+	r.RegisterError(errGone, http.StatusGone)
+
+	return r
+}
+
+func TestRegistryStatus(t *testing.T) {
+	tcs := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "ForANilError",
+			err:    nil,
+			status: http.StatusOK,
+		},
+		{
+			name:   "ForAnUnknownError",
+			err:    errors.New("unknown"),
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "ForARegisteredError",
+			err:    errNotFound,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ForADeeplyWrappedRegisteredError",
+			err:    errors.WithMessage(errors.Wrap(errors.New("sql: no rows"), errNotFound), "user %d", 42),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ForADifferentErrorWithTheRegisteredMessage",
+			err:    errors.New("not found"),
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "ForARegisteredType",
+			err:    errors.Wrap(validationError{"name"}),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "ForARegisteredCode",
+			err:    errors.Wrap(errors.New("db error"), errForbidden),
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "ForSeveralRegisteredErrors",
+			err:    errors.Wrap(errForbidden, errNotFound),
+			status: http.StatusNotFound,
+		},
+	}
+
+	r := newTestRegistry()
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if status := r.Status(tc.err); status != tc.status {
+				t.Errorf("Status(%v) != %d, got %d", tc.err, tc.status, status)
+			}
+		})
+	}
+}
+
+func TestRegistrySetDefaultStatus(t *testing.T) {
+	r := NewRegistry()
+	r.SetDefaultStatus(http.StatusBadGateway)
+
+	if status := r.Status(errors.New("unknown")); status != http.StatusBadGateway {
+		t.Errorf("Status() for an unknown error != %d, got %d", http.StatusBadGateway, status)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tcs := []struct {
+		name   string
+		fn     HandlerFunc
+		status int
+		body   string
+	}{
+		{
+			name: "ForASuccessfulHandler",
+			fn: func(w http.ResponseWriter, _ *http.Request) error {
+				_, _ = w.Write([]byte("ok"))
+				return nil
+			},
+			status: http.StatusOK,
+			body:   "ok",
+		},
+		{
+			name: "ForAFailedHandler",
+			fn: func(http.ResponseWriter, *http.Request) error {
+				return errors.Wrap(errors.New("sql: no rows"), errNotFound)
+			},
+			status: http.StatusNotFound,
+			body:   "Not Found",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h := Handler{Func: tc.fn, Registry: newTestRegistry()}
+
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tc.status {
+				t.Errorf("ServeHTTP() status != %d, got %d", tc.status, rec.Code)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != tc.body {
+				t.Errorf("ServeHTTP() body != %q, got %q", tc.body, body)
+			}
+		})
+	}
+}
+
+func TestHandlerForACustomRenderer(t *testing.T) {
+	var (
+		rec         = httptest.NewRecorder()
+		renderedErr error
+		h           = Handler{
+			Func:     func(http.ResponseWriter, *http.Request) error { return errNotFound },
+			Registry: newTestRegistry(),
+			Render: func(w http.ResponseWriter, _ *http.Request, err error, status int) {
+				renderedErr = err
+				w.WriteHeader(status)
+			},
+		}
+	)
+
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if renderedErr != errNotFound {
+		t.Errorf("ServeHTTP() must render the handler error %v, got %v", errNotFound, renderedErr)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("ServeHTTP() status != %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestHandlerFuncUsesTheDefaultRegistry(t *testing.T) {
+	rec := httptest.NewRecorder()
+	f := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return errors.New("unknown") })
+
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP() status != %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
@@ -0,0 +1,219 @@
+package httperrors
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ameteiko/errors"
+)
+
+// ProblemContentType is the media type of the RFC 7807 problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// problemCodeMember is the extension member carrying the error code.
+const problemCodeMember = "code"
+
+// errMsgSeparator joins the messages attached to the error into the problem detail.
+const errMsgSeparator = " : "
+
+// Problem is an RFC 7807 problem details object.
+// It conforms to the error interface, so a decoded problem is inspectable by errors.FetchByType().
+type Problem struct {
+	Type       string                 // A URI reference that identifies the problem type.
+	Title      string                 // A short summary of the problem type.
+	Status     int                    // The HTTP status code.
+	Detail     string                 // An explanation specific to this occurrence of the problem.
+	Instance   string                 // A URI reference that identifies this occurrence of the problem.
+	Code       errors.Code            // The error code, the "code" extension member.
+	Extensions map[string]interface{} // Other extension members.
+}
+
+// Error returns an error message.
+func (p *Problem) Error() string {
+	if p.Status == 0 {
+		return p.Title
+	}
+
+	return strconv.Itoa(p.Status) + " " + http.StatusText(p.Status)
+}
+
+// MarshalJSON returns the problem+json representation of the problem.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		doc[k] = v
+	}
+
+	setMember := func(name string, val interface{}, isSet bool) {
+		if isSet {
+			doc[name] = val
+		} else {
+			delete(doc, name)
+		}
+	}
+	setMember("type", p.Type, p.Type != "")
+	setMember("title", p.Title, p.Title != "")
+	setMember("status", p.Status, p.Status != 0)
+	setMember("detail", p.Detail, p.Detail != "")
+	setMember("instance", p.Instance, p.Instance != "")
+	setMember(problemCodeMember, p.Code, p.Code != "")
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes the problem+json document into the problem.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+	takeString := func(name string) string {
+		val, _ := doc[name].(string)
+		delete(doc, name)
+
+		return val
+	}
+	p.Type = takeString("type")
+	p.Title = takeString("title")
+	p.Detail = takeString("detail")
+	p.Instance = takeString("instance")
+	p.Code = errors.Code(takeString(problemCodeMember))
+	if status, ok := doc["status"].(float64); ok {
+		p.Status = int(status)
+	}
+	delete(doc, "status")
+
+	if len(doc) > 0 {
+		p.Extensions = doc
+	}
+
+	return nil
+}
+
+// Problem returns the problem details for the error.
+// The title is the message of the outermost registered sentinel found in the error or the status text, the detail
+// joins the messages attached to the error with errors.WithMessage() and the fields attached with errors.WithFields()
+// become extension members.
+func (r *Registry) Problem(err error) *Problem {
+	status := r.Status(err)
+	p := &Problem{
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     strings.Join(errors.Messages(err), errMsgSeparator),
+		Code:       errors.CodeOf(err),
+		Extensions: errors.Fields(err),
+	}
+	if sentinel := r.outermostError(err); sentinel != nil {
+		p.Title = sentinel.Error()
+	}
+
+	return p
+}
+
+// RenderProblem writes the problem details for the error as an application/problem+json response.
+// It conforms to the Renderer type: Handler{Func: f, Registry: r, Render: r.RenderProblem}.
+func (r *Registry) RenderProblem(w http.ResponseWriter, _ *http.Request, err error, status int) {
+	p := r.Problem(err)
+	p.Status = status
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// DecodeProblem decodes the problem+json document.
+func DecodeProblem(rd io.Reader) (*Problem, error) {
+	p := new(Problem)
+	if err := json.NewDecoder(rd).Decode(p); err != nil {
+		return nil, errors.WithMessage(err, "invalid problem document")
+	}
+
+	return p, nil
+}
+
+// ProblemError returns an error queue restored from the problem.
+// The queue contains the problem itself, the registered sentinel matching the problem code or title, the problem
+// detail attached as a message and the extension members attached as fields. So the sentinels survive the service hop
+// and can be matched with errors.Fetch().
+func (r *Registry) ProblemError(p *Problem) error {
+	err := errors.Wrap(p, r.problemError(p))
+	if p.Detail != "" {
+		err = errors.WithMessage(err, "%s", p.Detail)
+	}
+	if len(p.Extensions) > 0 {
+		keyvals := make([]interface{}, 0, len(p.Extensions)*2)
+		for k, v := range p.Extensions {
+			keyvals = append(keyvals, k, v)
+		}
+		err = errors.WithFields(err, keyvals...)
+	}
+	if p.Code != "" && errors.CodeOf(err) != p.Code {
+		err = errors.WithCode(err, p.Code)
+	}
+
+	return err
+}
+
+// ResponseError returns the error described by the response.
+// Returns nil for the responses with a status code less than 400. Responses that are not application/problem+json are
+// described by the status code only.
+func (r *Registry) ResponseError(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	p := &Problem{Title: http.StatusText(resp.StatusCode), Status: resp.StatusCode}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == ProblemContentType {
+		decoded, err := DecodeProblem(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, p)
+		}
+		p = decoded
+		if p.Status == 0 {
+			p.Status = resp.StatusCode
+		}
+	}
+
+	return r.ProblemError(p)
+}
+
+// outermostError returns the outermost registered sentinel found in the error.
+func (r *Registry) outermostError(err error) (sentinel error) {
+	sentinels := r.Errors()
+	errors.Walk(err, func(err error, _ int) bool {
+		if _, ok := err.(interface{ Unwrap() []error }); ok {
+			return true
+		}
+		for _, s := range sentinels {
+			if errors.Fetch(err, s) != nil {
+				sentinel = s
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return sentinel
+}
+
+// problemError returns the registered sentinel that has the problem code or the problem title as a message.
+func (r *Registry) problemError(p *Problem) error {
+	var titleMatch error
+	for _, s := range r.Errors() {
+		if c, ok := s.(interface{ Code() errors.Code }); ok && p.Code != "" && c.Code() == p.Code {
+			return s
+		}
+		if titleMatch == nil && s.Error() == p.Title {
+			titleMatch = s
+		}
+	}
+
+	return titleMatch
+}
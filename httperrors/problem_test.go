@@ -0,0 +1,217 @@
+package httperrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ameteiko/errors"
+)
+
+func TestRegistryProblem(t *testing.T) {
+	var (
+		r   = newTestRegistry()
+		err = errors.WithFields(
+			errors.WithMessage(errors.Wrap(errors.New("sql: no rows"), errNotFound), "user %d", 42),
+			"user_id", 42,
+		)
+		expected = &Problem{
+			Title:      "not found",
+			Status:     http.StatusNotFound,
+			Detail:     "user 42",
+			Extensions: map[string]interface{}{"user_id": 42},
+		}
+	)
+
+	if p := r.Problem(err); !reflect.DeepEqual(p, expected) {
+		t.Errorf("Problem(%v) != %+v, got %+v", err, expected, p)
+	}
+}
+
+func TestRegistryProblemForAnUnregisteredError(t *testing.T) {
+	var (
+		r        = newTestRegistry()
+		err      = errors.Wrap(errors.New("db error"), errForbidden)
+		expected = &Problem{Title: "Forbidden", Status: http.StatusForbidden, Code: "forbidden"}
+	)
+
+	if p := r.Problem(err); !reflect.DeepEqual(p, expected) {
+		t.Errorf("Problem(%v) != %+v, got %+v", err, expected, p)
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	var (
+		p = &Problem{
+			Type:       "https://example.com/not-found",
+			Title:      "not found",
+			Status:     http.StatusNotFound,
+			Detail:     "user 42",
+			Code:       "not_found",
+			Extensions: map[string]interface{}{"user_id": float64(42), "title": "ignored"},
+		}
+		decoded Problem
+	)
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("json.Marshal(%+v) returned an error: %v", p, err)
+	}
+	expectedJSON := `{"code":"not_found","detail":"user 42","status":404,"title":"not found",` +
+		`"type":"https://example.com/not-found","user_id":42}`
+	if string(data) != expectedJSON {
+		t.Errorf("json.Marshal(%+v) %s != %s", p, expectedJSON, data)
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(%s) returned an error: %v", data, err)
+	}
+	p.Extensions = map[string]interface{}{"user_id": float64(42)}
+	if !reflect.DeepEqual(&decoded, p) {
+		t.Errorf("json.Unmarshal(%s) != %+v, got %+v", data, p, decoded)
+	}
+}
+
+func TestDecodeProblemForAnInvalidDocument(t *testing.T) {
+	if p, err := DecodeProblem(strings.NewReader("{")); err == nil {
+		t.Errorf("DecodeProblem({) must return an error, got %+v", p)
+	}
+}
+
+func TestProblemRoundTrip(t *testing.T) {
+	var (
+		r = newTestRegistry()
+		h = Handler{
+			Func: func(http.ResponseWriter, *http.Request) error {
+				err := errors.WithMessage(errors.Wrap(errors.New("sql: no rows"), errNotFound), "user %d", 42)
+				return errors.WithFields(err, "user_id", 42)
+			},
+			Registry: r,
+			Render:   r.RenderProblem,
+		}
+		srv = httptest.NewServer(h)
+	)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("http.Get() returned an error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Content-Type != %q, got %q", ProblemContentType, ct)
+	}
+
+	respErr := r.ResponseError(resp)
+	if respErr == nil {
+		t.Fatalf("ResponseError() must return an error for a %d response", resp.StatusCode)
+	}
+	if errors.Fetch(respErr, errNotFound) != errNotFound {
+		t.Errorf("ResponseError() must contain %v, got %v", errNotFound, respErr)
+	}
+	if msgs := errors.Messages(respErr); !reflect.DeepEqual(msgs, []string{"user 42"}) {
+		t.Errorf("ResponseError() must contain the %q message, got %v", "user 42", msgs)
+	}
+	if fields := errors.Fields(respErr); fields["user_id"] != float64(42) {
+		t.Errorf("ResponseError() must contain the user_id field, got %v", fields)
+	}
+	if p, ok := errors.FetchByType(respErr, (*Problem)(nil)).(*Problem); !ok || p.Status != http.StatusNotFound {
+		t.Errorf("ResponseError() must contain the problem details, got %v", respErr)
+	}
+	if status := r.Status(respErr); status != http.StatusNotFound {
+		t.Errorf("Status(ResponseError()) != %d, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	r := newTestRegistry()
+
+	tcs := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		targetErr   error
+		code        errors.Code
+		msg         string
+	}{
+		{
+			name:   "ForASuccessfulResponse",
+			status: http.StatusOK,
+		},
+		{
+			name:        "ForAPlainTextResponse",
+			status:      http.StatusNotFound,
+			contentType: "text/plain",
+			body:        "Not Found",
+			msg:         "404 Not Found",
+		},
+		{
+			name:        "ForACodedProblem",
+			status:      http.StatusGone,
+			contentType: ProblemContentType + "; charset=utf-8",
+			body:        `{"title":"Gone","status":410,"code":"gone"}`,
+			targetErr:   errGone,
+			code:        "gone",
+			msg:         "resource is gone : 410 Gone",
+		},
+		{
+			name:        "ForAProblemWithARegisteredTitle",
+			status:      http.StatusNotFound,
+			contentType: ProblemContentType,
+			body:        `{"title":"not found","status":404,"detail":"user 42"}`,
+			targetErr:   errNotFound,
+			msg:         "user 42 : not found : 404 Not Found",
+		},
+		{
+			name:        "ForAnUnknownProblem",
+			status:      http.StatusConflict,
+			contentType: ProblemContentType,
+			body:        `{"title":"conflict","code":"conflict"}`,
+			code:        "conflict",
+			msg:         "409 Conflict",
+		},
+		{
+			name:        "ForAnInvalidProblem",
+			status:      http.StatusBadGateway,
+			contentType: ProblemContentType,
+			body:        `{`,
+			msg:         "502 Bad Gateway : invalid problem document : unexpected EOF",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Content-Type", tc.contentType)
+			rec.WriteHeader(tc.status)
+			_, _ = rec.WriteString(tc.body)
+
+			err := r.ResponseError(rec.Result())
+
+			if tc.status < http.StatusBadRequest {
+				if err != nil {
+					t.Errorf("ResponseError() must return nil for a %d response, got %v", tc.status, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ResponseError() must return an error for a %d response", tc.status)
+			}
+			if err.Error() != tc.msg {
+				t.Errorf("ResponseError() error message mismatch, %q != %q", tc.msg, err.Error())
+			}
+			if tc.targetErr != nil && errors.Fetch(err, tc.targetErr) == nil {
+				t.Errorf("ResponseError() must contain %v, got %v", tc.targetErr, err)
+			}
+			if code := errors.CodeOf(err); code != tc.code {
+				t.Errorf("CodeOf(ResponseError()) != %q, got %q", tc.code, code)
+			}
+		})
+	}
+}
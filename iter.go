@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the errors contained in err in the outer-to-inner order.
+// Error queues are expanded into their errors and errors wrapped by means of the standard library are followed, so the
+// iterator yields the same errors Fetch() inspects. A plain error yields itself and the errors it wraps.
+func All(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		if !isErrNil(err) {
+			yieldForward(err, yield)
+		}
+	}
+}
+
+// Backward returns an iterator over the errors contained in err in the inner-to-outer order, see All().
+func Backward(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		if !isErrNil(err) {
+			yieldBackward(err, yield)
+		}
+	}
+}
+
+// Len returns the number of errors contained in err, see All().
+func Len(err error) (n int) {
+	for range All(err) {
+		n++
+	}
+
+	return n
+}
+
+// At returns the i-th error contained in err in the outer-to-inner order, see All().
+// Returns nil if i is out of range.
+func At(err error, i int) error {
+	if i < 0 {
+		return nil
+	}
+
+	for e := range All(err) {
+		if i == 0 {
+			return e
+		}
+		i--
+	}
+
+	return nil
+}
+
+// yieldForward yields err, unless it is a queue, and then its children in the outer-to-inner order.
+// Returns false if the iteration was stopped.
+func yieldForward(err error, yield func(error) bool) bool {
+	q, isQueue := err.(*queue)
+	if !isQueue {
+		if !yield(err) {
+			return false
+		}
+		for _, wrappedErr := range unwrapErr(err) {
+			if !yieldForward(wrappedErr, yield) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for i := len(q.errs) - 1; i >= 0; i-- {
+		if !yieldForward(q.errs[i], yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// yieldBackward yields the children of err in the inner-to-outer order and then err itself, unless it is a queue.
+// Returns false if the iteration was stopped.
+func yieldBackward(err error, yield func(error) bool) bool {
+	q, isQueue := err.(*queue)
+	if !isQueue {
+		wrappedErrs := unwrapErr(err)
+		for i := len(wrappedErrs) - 1; i >= 0; i-- {
+			if !yieldBackward(wrappedErrs[i], yield) {
+				return false
+			}
+		}
+
+		return yield(err)
+	}
+
+	for _, childErr := range q.errs {
+		if !yieldBackward(childErr, yield) {
+			return false
+		}
+	}
+
+	return true
+}
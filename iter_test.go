@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		err3 = New("3")
+	)
+	stdlibErr := fmt.Errorf("context: %w", err2)
+
+	tcs := []struct {
+		name string
+		err  error
+		errs []error
+	}{
+		{
+			name: "ForANilError",
+			err:  nil,
+			errs: nil,
+		},
+		{
+			name: "ForAPlainError",
+			err:  err1,
+			errs: []error{err1},
+		},
+		{
+			name: "ForAnErrorWrappedByTheStdlib",
+			err:  stdlibErr,
+			errs: []error{stdlibErr, err2},
+		},
+		{
+			name: "ForAQueue",
+			err:  Wrap(err1, err2, err3),
+			errs: []error{err3, err2, err1},
+		},
+		{
+			name: "ForNestedQueues",
+			err:  Wrap(Wrap(err1, stdlibErr), err3),
+			errs: []error{err3, stdlibErr, err2, err1},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if errs := slices.Collect(All(tc.err)); !reflect.DeepEqual(errs, tc.errs) {
+				t.Errorf("All(%v) != %v, got %v", tc.err, tc.errs, errs)
+			}
+
+			backwardErrs := slices.Clone(tc.errs)
+			slices.Reverse(backwardErrs)
+			if errs := slices.Collect(Backward(tc.err)); !reflect.DeepEqual(errs, backwardErrs) {
+				t.Errorf("Backward(%v) != %v, got %v", tc.err, backwardErrs, errs)
+			}
+
+			if n := Len(tc.err); n != len(tc.errs) {
+				t.Errorf("Len(%v) != %d, got %d", tc.err, len(tc.errs), n)
+			}
+			for i, err := range tc.errs {
+				if res := At(tc.err, i); res != err {
+					t.Errorf("At(%v, %d) != %v, got %v", tc.err, i, err, res)
+				}
+			}
+		})
+	}
+}
+
+func TestAllStops(t *testing.T) {
+	err := Wrap(New("1"), New("2"), New("3"))
+
+	for name, seq := range map[string]func(error) iter.Seq[error]{"All": All, "Backward": Backward} {
+		var visited int
+		for range seq(err) {
+			visited++
+			break
+		}
+		if visited != 1 {
+			t.Errorf("%s() must stop the iteration, visited %d errors", name, visited)
+		}
+	}
+}
+
+func TestAtForAnIndexOutOfRange(t *testing.T) {
+	err := Wrap(New("1"))
+
+	for _, i := range []int{-1, 1} {
+		if res := At(err, i); res != nil {
+			t.Errorf("At(%v, %d) must return nil, got %v", err, i, res)
+		}
+	}
+}
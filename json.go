@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonSchemaVersion is the version of the JSON document produced by queue.MarshalJSON().
+// It is incremented on every change that is not backward compatible.
+const jsonSchemaVersion = 1
+
+// jsonQueue is the JSON representation of the error queue.
+type jsonQueue struct {
+	Version int                    `json:"version"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Errors  []jsonError            `json:"errors"`
+	Stack   []jsonFrame            `json:"stack,omitempty"`
+}
+
+// jsonError is the JSON representation of a queue error.
+type jsonError struct {
+	Message string                 `json:"message"`
+	Type    string                 `json:"type"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// jsonFrame is the JSON representation of a stacktrace frame.
+type jsonFrame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+// MarshalJSON returns the JSON representation of the error queue.
+//
+// The document has the following schema (version 1):
+//
+//	{
+//	  "version": 1,                     // Schema version.
+//	  "message": "outer : inner",       // The Error() message.
+//	  "fields": {"key": "value"},       // Optional, fields attached to the queue (see Fields()).
+//	  "errors": [                       // Queue errors in the outer-to-inner order.
+//	    {
+//	      "message": "outer",           // The Error() message of the error.
+//	      "type": "*errors.errorString", // Go type of the error.
+//	      "fields": {"key": "value"}    // Optional, provided by the Fields() map[string]interface{} method.
+//	    }
+//	  ],
+//	  "stack": [                        // Optional, the error origin stacktrace.
+//	    {"file": "github.com/ameteiko/errors/errors.go", "line": 52, "function": "github.com/ameteiko/errors.Wrap"}
+//	  ]
+//	}
+func (q *queue) MarshalJSON() ([]byte, error) {
+	jq := jsonQueue{Version: jsonSchemaVersion, Message: q.Error(), Fields: Fields(q), Errors: []jsonError{}}
+	for _, err := range q.getErrors() {
+		je := jsonError{Message: err.Error(), Type: fmt.Sprintf("%T", err)}
+		if f, ok := err.(fielder); ok {
+			je.Fields = f.Fields()
+		}
+		jq.Errors = append(jq.Errors, je)
+	}
+
+	if s := originStacktrace(q.getStacktrace()); s != nil {
+		for _, f := range CurrentFrameFilter().Filter(s.Frames()) {
+			jq.Stack = append(jq.Stack, jsonFrame{File: sanitizeFilename(f.File, f.Package), Line: f.Line, Function: f.Function})
+		}
+	}
+
+	return json.Marshal(jq)
+}
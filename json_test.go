@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldsError is an error that provides its data as fields.
+type fieldsError struct{ id int }
+
+func (e fieldsError) Error() string                  { return "fields error" }
+func (e fieldsError) Fields() map[string]interface{} { return map[string]interface{}{"id": e.id} }
+
+func TestMarshalJSON(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	var (
+		err1 = New("1")
+		err2 = fieldsError{id: 2}
+		q    = Wrap(err1, err2)
+		doc  jsonQueue
+	)
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal(%v) returned an error: %v", q, err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal(%s) returned an error: %v", data, err)
+	}
+
+	if doc.Version != jsonSchemaVersion {
+		t.Errorf("json.Marshal(%v) version mismatch, %d != %d", q, jsonSchemaVersion, doc.Version)
+	}
+	if doc.Message != q.Error() {
+		t.Errorf("json.Marshal(%v) message mismatch, %q != %q", q, q.Error(), doc.Message)
+	}
+	expectedErrors := []jsonError{
+		{Message: "fields error", Type: "errors.fieldsError", Fields: map[string]interface{}{"id": float64(2)}},
+		{Message: "1", Type: "*errors.errorString"},
+	}
+	if !reflect.DeepEqual(doc.Errors, expectedErrors) {
+		t.Errorf("json.Marshal(%v) errors mismatch, %v != %v", q, expectedErrors, doc.Errors)
+	}
+	if len(doc.Stack) < 2 || !strings.HasSuffix(doc.Stack[1].Function, "TestMarshalJSON") {
+		t.Fatalf("json.Marshal(%v) must provide the stack of the Wrap() call, got %v", q, doc.Stack)
+	}
+	if doc.Stack[1].Line == 0 || !strings.HasSuffix(doc.Stack[1].File, "json_test.go") {
+		t.Errorf("json.Marshal(%v) must provide the frame file and line, got %v", q, doc.Stack[1])
+	}
+}
+
+func TestMarshalJSONForAQueueWithoutStacktrace(t *testing.T) {
+	q := &queue{errs: []error{New("1")}}
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal(%v) returned an error: %v", q, err)
+	}
+
+	expected := `{"version":1,"message":"1","errors":[{"message":"1","type":"*errors.errorString"}]}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal(%v) %s != %s", q, expected, data)
+	}
+}
+
+func TestMarshalJSONForAttachedFields(t *testing.T) {
+	var (
+		q   = WithFields(Wrap(New("1")), "id", 1)
+		doc jsonQueue
+	)
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal(%v) returned an error: %v", q, err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal(%s) returned an error: %v", data, err)
+	}
+
+	expected := map[string]interface{}{"id": float64(1)}
+	if !reflect.DeepEqual(doc.Fields, expected) {
+		t.Errorf("json.Marshal(%v) fields mismatch, %v != %v", q, expected, doc.Fields)
+	}
+}
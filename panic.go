@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// panicFramesDepth is the number of frames captured in addition to the stack depth to skip the frames of the deferred
+// function and the runtime panic handling.
+const panicFramesDepth = 8
+
+// PanicError is an error describing a recovered panic.
+// If the panic value is an error, PanicError wraps it, so it is inspectable by Fetch() and FetchByType().
+type PanicError struct {
+	Value interface{} // The value passed to panic().
+	Stack Stacktrace  // The stacktrace at the moment of panic.
+}
+
+// Error returns an error message.
+func (e *PanicError) Error() string {
+	return "panic: " + fmt.Sprint(e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// Recover recovers a panic and converts it into an error queue containing a PanicError.
+// It must be deferred directly and errp must point to the error to be returned:
+//
+//	func process() (err error) {
+//		defer errors.Recover(&err)
+//		// ...
+//	}
+//
+// If *errp is already set, the PanicError is wrapped around it. The queue stacktrace is the one at the moment of panic
+// rather than at the moment of recovery, it is captured according to the stack mode, see SetStackMode(). The location
+// of the panic is recorded as the call site for the wrap trail, see WrapTrail().
+func Recover(errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	panicErr := &PanicError{Value: v, Stack: newPanicStacktrace()}
+	panicQ := &queue{errs: []error{panicErr}, stackPolicy: CurrentStackPolicy()}
+	if len(panicErr.Stack) > 0 {
+		panicQ.stacktrace = panicErr.Stack
+		panicQ.caller = panicErr.Stack[0]
+	}
+	if isErrNil(*errp) {
+		*errp = panicQ
+		return
+	}
+
+	*errp = &queue{errs: []error{*errp, panicQ}, stacktrace: panicQ.stacktrace, stackPolicy: panicQ.stackPolicy}
+}
+
+// newPanicStacktrace returns the stacktrace at the moment of panic.
+// It is supposed to be called by the deferred function recovering the panic: the frames of the deferred function,
+// runtime.gopanic() and the runtime functions raising run-time panics are skipped.
+func newPanicStacktrace() Stacktrace {
+	mode := CurrentStackMode()
+	if mode == StackOff {
+		return nil
+	}
+
+	depth := CurrentStackDepth()
+	pcs := make(Stacktrace, depth+panicFramesDepth)
+	// Skipping runtime.Callers() and errors.newPanicStacktrace().
+	n := runtime.Callers(2, pcs)
+
+	i := panicFrameIndex(pcs[:n])
+	if i < 0 {
+		return nil
+	}
+
+	if mode == StackCaller {
+		depth = 1
+	}
+	if n-i > depth {
+		n = i + depth
+	}
+
+	return pcs[i:n]
+}
+
+// panicFrameIndex returns the index of the program counter of the function that panicked, i.e. the first one after
+// runtime.gopanic() that doesn't belong to the runtime. Frames are resolved with runtime.CallersFrames(), so inlined
+// calls are taken into account. Returns -1 if there is no panic in the stack.
+func panicFrameIndex(pcs []uintptr) int {
+	frames := runtime.CallersFrames(pcs)
+	panicIndex := -1
+	for {
+		f, more := frames.Next()
+		switch {
+		case panicIndex < 0 && f.Function == "runtime.gopanic":
+			panicIndex = pcIndex(pcs, 0, f.PC)
+		case panicIndex >= 0 && !strings.HasPrefix(f.Function, "runtime."):
+			return pcIndex(pcs, panicIndex+1, f.PC)
+		}
+
+		if !more {
+			return -1
+		}
+	}
+}
+
+// pcIndex returns the index of the program counter of the frame starting the search with the from index.
+// The frame program counter is the one of the call instruction, while the captured ones are the return addresses.
+func pcIndex(pcs []uintptr, from int, framePC uintptr) int {
+	for i := from; i < len(pcs); i++ {
+		if pcs[i] == framePC || pcs[i]-1 == framePC {
+			return i
+		}
+	}
+
+	return -1
+}
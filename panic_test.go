@@ -0,0 +1,135 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	errPanic     = New("panic error")
+	errRecovered = New("recovered error")
+)
+
+// panicWith panics with the value, it is the function the panic stacktrace must start with.
+func panicWith(v interface{}) {
+	if v == nil {
+		var m map[string]int
+		m["key"] = 1
+	}
+	panic(v)
+}
+
+// recoverPanic calls panicWith() and recovers the panic into the error.
+func recoverPanic(err error, v interface{}) (resErr error) {
+	resErr = err
+	defer Recover(&resErr)
+
+	panicWith(v)
+
+	return resErr
+}
+
+func TestRecover(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	tcs := []struct {
+		name      string
+		err       error
+		value     interface{}
+		msg       string
+		targetErr error
+	}{
+		{
+			name:  "ForAStringValue",
+			value: "boom",
+			msg:   "panic: boom",
+		},
+		{
+			name:      "ForAnErrorValue",
+			value:     errPanic,
+			msg:       "panic: panic error",
+			targetErr: errPanic,
+		},
+		{
+			name:  "ForARuntimeError",
+			value: nil,
+			msg:   "panic: assignment to entry in nil map",
+		},
+		{
+			name:      "ForAnExistingError",
+			err:       Wrap(errRecovered),
+			value:     "boom",
+			msg:       "panic: boom : recovered error",
+			targetErr: errRecovered,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := recoverPanic(tc.err, tc.value)
+
+			if err == nil || err.Error() != tc.msg {
+				t.Fatalf("Recover() error message mismatch, %q != %v", tc.msg, err)
+			}
+			panicErr, ok := FetchByType(err, (*PanicError)(nil)).(*PanicError)
+			if !ok {
+				t.Fatalf("Recover() must return an error containing PanicError, got %v", err)
+			}
+			if tc.value != nil && panicErr.Value != tc.value {
+				t.Errorf("PanicError.Value != %v, got %v", tc.value, panicErr.Value)
+			}
+			if tc.targetErr != nil && Fetch(err, tc.targetErr) != tc.targetErr {
+				t.Errorf("Recover() must return an error containing %v, got %v", tc.targetErr, err)
+			}
+
+			frames := StackTrace(err).Frames()
+			if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, ".panicWith") {
+				t.Errorf("Recover() must capture the stacktrace at the moment of panic, got %v", frames)
+			}
+			if len(panicErr.Stack) == 0 || &panicErr.Stack[0] != &StackTrace(err)[0] {
+				t.Errorf("PanicError.Stack must be the stacktrace of the error, got %v", panicErr.Stack)
+			}
+		})
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	err1 := New("1")
+	err := func() (err error) {
+		defer Recover(&err)
+		return err1
+	}()
+
+	if err != err1 {
+		t.Errorf("Recover() mustn't change the error if there is no panic, got %v", err)
+	}
+}
+
+func TestRecoverForTheStackModes(t *testing.T) {
+	for _, mode := range []StackMode{StackCaller, StackOff} {
+		t.Run(mode.String(), func(t *testing.T) {
+			setStackConfig(t, mode, stacktraceDepth)
+
+			frames := StackTrace(recoverPanic(nil, "boom")).Frames()
+
+			if mode == StackOff && len(frames) != 0 {
+				t.Errorf("Recover() mustn't capture a stacktrace in the %v mode, got %v", mode, frames)
+			}
+			if mode == StackCaller && (len(frames) != 1 || !strings.HasSuffix(frames[0].Function, ".panicWith")) {
+				t.Errorf("Recover() must capture the panicking function in the %v mode, got %v", mode, frames)
+			}
+		})
+	}
+}
+
+func TestFormatForARecoveredPanic(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	output := fmt.Sprintf("%+v", recoverPanic(nil, "boom"))
+
+	if !strings.HasPrefix(output, "panic: boom\n\t") || !strings.Contains(output, "errors.panicWith()") {
+		t.Errorf("%%+v must print the stacktrace at the moment of panic, got %q", output)
+	}
+}
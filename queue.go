@@ -3,22 +3,40 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"time"
 )
 
 // errMsgSeparator joins error messages in form of "outer error : inner error".
 const errMsgSeparator = " : "
 
 // queue object queues application errors into an ordered collection.
-// All errors are stored in LIFO order, that's why getErrors() reverses the list.
+// All errors are stored in LIFO order, that's why getErrors() reverses the list. Wrapped queues are stored as is, so
+// the queue is a node of the wrapping tree which leaves are application errors.
 type queue struct {
-	errs       []error       // The Double-Ended Queue with errors.
-	stacktrace fmt.Formatter // Stacktrace at the moment of creation.
+	errs        []error                // The Double-Ended Queue with errors and wrapped queues.
+	stacktrace  fmt.Formatter          // Stacktrace at the moment of creation.
+	fields      map[string]interface{} // Fields attached with WithFields().
+	code        Code                   // Code attached with WithCode().
+	msg         error                  // Message attached with WithMessage() or WrapWithMessage().
+	stackPolicy StackPolicy            // Stack policy at the moment of creation.
+	retry       retryClass             // Retry classification attached with MarkRetryable() or MarkPermanent().
+	retryAfter  time.Duration          // Retry-after duration attached with WithRetryAfter().
+	caller      uintptr                // Program counter of the Wrap(), WithMessage(), etc. caller.
 }
 
 // newQueue returns a new queue instance with a stacktrace data at the moment of invocation.
+// The stacktrace is captured according to the stack mode, see SetStackMode(). The caller of the exported function is
+// recorded for the wrap trail, see WrapTrail().
 // Contract: all errors from the errs list are not nil.
 func newQueue(errs ...error) *queue {
-	return &queue{errs: errs, stacktrace: newStacktrace()}
+	s, caller := newStacktrace()
+	q := &queue{errs: errs, stackPolicy: CurrentStackPolicy(), caller: caller}
+	if len(s) > 0 {
+		q.stacktrace = s
+	}
+
+	return q
 }
 
 // Error returns an error message.
@@ -35,17 +53,111 @@ func (q *queue) Error() (errMsg string) {
 }
 
 // Format formats an error message for the queue object.
-// %+v additionally prints out an error stacktrace.
+// Supported verbs:
+//   - %v, %s: the error message, %+v additionally prints out an error stacktrace and the wrap trail if the error has
+//     passed through several wrapping calls, see WrapTrail();
+//   - %q: the double-quoted error message;
+//   - %x, %X: the hex encoded error message;
+//   - %#v: the Go-syntax representation of the queue with its errors and attached data;
+//   - %+#v: the verbose tree-style representation with the locations and the stacktrace, see Render().
+//
+// Width, precision and flags are applied to the whole error message. Errors implementing fmt.Formatter are formatted
+// with the same verb and flags for %v and %s.
 func (q *queue) Format(st fmt.State, verb rune) {
-	_, _ = st.Write([]byte(q.Error()))
-	if verb == 'v' && st.Flag('+') {
+	switch {
+	case verb == 'v' && st.Flag('#') && st.Flag('+'):
+		_, _ = st.Write([]byte(Render(q, RenderOptions{Locations: true, Stack: true})))
+	case verb == 'v' && st.Flag('#'):
+		q.formatGoSyntax(st)
+	case verb == 'v' || verb == 's':
+		_, _ = fmt.Fprintf(st, fmt.FormatString(st, 's'), q.formatMessage("%"+formatFlags(st)+string(verb)))
+		if verb == 'v' && st.Flag('+') {
+			q.formatStacktrace(st, verb)
+		}
+	case verb == 'q' || verb == 'x' || verb == 'X':
+		_, _ = fmt.Fprintf(st, fmt.FormatString(st, verb), q.formatMessage("%s"))
+	default:
+		_, _ = fmt.Fprintf(st, "%%!%c(%T=%s)", verb, q, q.Error())
+	}
+}
+
+// formatStacktrace writes the stacktrace and the wrap trail of the queue if the trail has more than one call.
+func (q *queue) formatStacktrace(st fmt.State, verb rune) {
+	s := q.getStacktrace()
+	if s != nil {
+		_, _ = st.Write([]byte("\n"))
+		s.Format(st, verb)
+	}
+
+	trail := WrapTrail(q)
+	if len(trail) < 2 {
+		return
+	}
+	if s == nil {
 		_, _ = st.Write([]byte("\n"))
-		q.stacktrace.Format(st, verb)
+	}
+	_, _ = st.Write([]byte("wrap trail:\n"))
+	for _, f := range trail {
+		_, _ = st.Write([]byte("\t" + formatFrame(f) + "\n"))
 	}
 }
 
-// getErrors returns the errors in reverse order.
-func (q *queue) getErrors() []error {
+// formatMessage returns the error message which errors implementing fmt.Formatter are formatted with the format for.
+func (q *queue) formatMessage(format string) string {
+	buf := new(bytes.Buffer)
+	for _, err := range q.getErrors() {
+		if buf.Len() != 0 {
+			buf.WriteString(errMsgSeparator)
+		}
+		if _, ok := err.(fmt.Formatter); ok {
+			_, _ = fmt.Fprintf(buf, format, err)
+			continue
+		}
+		buf.WriteString(err.Error())
+	}
+
+	return buf.String()
+}
+
+// formatGoSyntax writes the Go-syntax representation of the queue: its children in the outer-to-inner order and the
+// attached message, code and fields.
+func (q *queue) formatGoSyntax(st fmt.State) {
+	_, _ = fmt.Fprintf(st, "&%T{errs:[]error{", *q)
+	for i, err := range q.Unwrap() {
+		if i > 0 {
+			_, _ = st.Write([]byte(", "))
+		}
+		_, _ = fmt.Fprintf(st, "%#v", err)
+	}
+	_, _ = st.Write([]byte("}"))
+
+	if q.msg != nil {
+		_, _ = fmt.Fprintf(st, ", msg:%q", q.msg)
+	}
+	if q.code != "" {
+		_, _ = fmt.Fprintf(st, ", code:%q", q.code)
+	}
+	if len(q.fields) > 0 {
+		_, _ = fmt.Fprintf(st, ", fields:%#v", q.fields)
+	}
+	_, _ = st.Write([]byte("}"))
+}
+
+// formatFlags returns the flags of the formatting state, e.g. "+#".
+func formatFlags(st fmt.State) string {
+	var flags []byte
+	for _, flag := range []byte("+-# 0") {
+		if st.Flag(int(flag)) {
+			flags = append(flags, flag)
+		}
+	}
+
+	return string(flags)
+}
+
+// Unwrap returns the queue children (errors and wrapped queues) in the outer-to-inner order.
+// It makes the queue inspectable by the standard library errors.Is() and errors.As() functions.
+func (q *queue) Unwrap() []error {
 	errsLen := len(q.errs)
 	errs := make([]error, errsLen)
 	for i := range q.errs {
@@ -54,3 +166,108 @@ func (q *queue) getErrors() []error {
 
 	return errs
 }
+
+// Is reports whether one of the queue direct members, that are not queues, is targetErr.
+// Members are matched by identity or by their own Is() method. The standard library errors.Is() unwraps the wrapped
+// queues and errors itself, so Is() doesn't traverse them to keep the inspection linear.
+func (q *queue) Is(targetErr error) bool {
+	if isErrNil(targetErr) {
+		return false
+	}
+
+	isComparable := reflect.TypeOf(targetErr).Comparable()
+	for _, err := range q.errs {
+		if _, ok := err.(*queue); ok {
+			continue
+		}
+		if isComparable && err == targetErr {
+			return true
+		}
+		if e, ok := err.(interface{ Is(error) bool }); ok && e.Is(targetErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As assigns the first queue error matching the target type to the target.
+// Besides the standard library rules it follows FetchByType() ones: an error value matches a pointer type target and
+// vice versa.
+func (q *queue) As(target interface{}) bool {
+	targetVal := reflect.ValueOf(target)
+	if !targetVal.IsValid() || targetVal.Kind() != reflect.Ptr || targetVal.IsNil() {
+		return false
+	}
+
+	targetElem := targetVal.Type().Elem()
+	for _, err := range collectErrors(q) {
+		errVal := reflect.ValueOf(err)
+		errType := errVal.Type()
+		switch {
+		case errType.AssignableTo(targetElem):
+			targetVal.Elem().Set(errVal)
+		case errType.Kind() == reflect.Ptr && errType.Elem() == targetElem && !errVal.IsNil():
+			targetVal.Elem().Set(errVal.Elem())
+		case targetElem.Kind() == reflect.Ptr && targetElem.Elem() == errType:
+			errPtr := reflect.New(errType)
+			errPtr.Elem().Set(errVal)
+			targetVal.Elem().Set(errPtr)
+		default:
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// getErrors returns the errors of the queue and all wrapped queues in reverse order.
+func (q *queue) getErrors() []error {
+	errs := make([]error, 0, len(q.errs))
+	for _, err := range q.Unwrap() {
+		if errQ, ok := err.(*queue); ok {
+			errs = append(errs, errQ.getErrors()...)
+			continue
+		}
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// getStacktrace returns the stacktrace describing the error origin.
+// If the queue wraps exactly one queue with a stacktrace, its stacktrace is reused. If there are several of them, the
+// result depends on the stack policy the queue was created with, see SetStackPolicy(). Otherwise the own one is
+// returned.
+func (q *queue) getStacktrace() fmt.Formatter {
+	var wrapped stacktraces
+	for _, err := range q.Unwrap() {
+		if errQ, ok := err.(*queue); ok {
+			if s := errQ.getStacktrace(); s != nil {
+				wrapped = append(wrapped, labelledStacktrace{err: errQ, stacktrace: s})
+			}
+		}
+	}
+
+	switch {
+	case len(wrapped) == 0:
+		return q.stacktrace
+	case len(wrapped) == 1:
+		return wrapped[0].stacktrace
+	}
+
+	switch q.stackPolicy {
+	case StackPolicyKeepInnermost:
+		return wrapped[len(wrapped)-1].stacktrace
+	case StackPolicyKeepAll:
+		return wrapped
+	default:
+		if q.stacktrace == nil {
+			return wrapped[len(wrapped)-1].stacktrace
+		}
+
+		return q.stacktrace
+	}
+}
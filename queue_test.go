@@ -1,8 +1,10 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestError(t *testing.T) {
@@ -58,7 +60,427 @@ func TestFormatForVFlag(t *testing.T) {
 	}
 }
 
+func TestFormat(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		q    = newQueue(err1, err2)
+	)
+	q.stacktrace = &formatterStub{"trace"}
+
+	tcs := []struct {
+		name   string
+		format string
+		err    error
+		output string
+	}{
+		{
+			name:   "ForV",
+			format: "%v",
+			err:    q,
+			output: "2 : 1",
+		},
+		{
+			name:   "ForS",
+			format: "%s",
+			err:    q,
+			output: "2 : 1",
+		},
+		{
+			name:   "ForPlusS",
+			format: "%+s",
+			err:    q,
+			output: "2 : 1",
+		},
+		{
+			name:   "ForQ",
+			format: "%q",
+			err:    q,
+			output: `"2 : 1"`,
+		},
+		{
+			name:   "ForSharpQ",
+			format: "%#q",
+			err:    q,
+			output: "`2 : 1`",
+		},
+		{
+			name:   "ForX",
+			format: "%x",
+			err:    newQueue(New("ab")),
+			output: "6162",
+		},
+		{
+			name:   "ForUpperX",
+			format: "% X",
+			err:    newQueue(New("ab")),
+			output: "61 62",
+		},
+		{
+			name:   "ForWidth",
+			format: "[%7v]",
+			err:    q,
+			output: "[  2 : 1]",
+		},
+		{
+			name:   "ForLeftAlignedWidth",
+			format: "[%-7s]",
+			err:    q,
+			output: "[2 : 1  ]",
+		},
+		{
+			name:   "ForPrecision",
+			format: "%.3s",
+			err:    q,
+			output: "2 :",
+		},
+		{
+			name:   "ForWidthAndPrecisionWithQ",
+			format: "%8.3q",
+			err:    q,
+			output: `   "2 :"`,
+		},
+		{
+			name:   "ForWidthWithPlusV",
+			format: "%+6v",
+			err:    q,
+			output: " 2 : 1\ntrace",
+		},
+		{
+			name:   "ForAnUnsupportedVerb",
+			format: "%d",
+			err:    q,
+			output: "%!d(*errors.queue=2 : 1)",
+		},
+		{
+			name:   "ForSharpV",
+			format: "%#v",
+			err:    &queue{errs: []error{customError{"1"}, &queue{errs: []error{customError{"2"}}}}},
+			output: `&errors.queue{errs:[]error{&errors.queue{errs:[]error{errors.customError{msg:"2"}}}, ` +
+				`errors.customError{msg:"1"}}}`,
+		},
+		{
+			name:   "ForSharpVWithAttachedData",
+			format: "%#v",
+			err:    WithFields(WithCode(WithMessage(customError{"1"}, "2"), "code"), "key", 1),
+			output: `&errors.queue{errs:[]error{&errors.queue{errs:[]error{&errors.queue{errs:[]error{` +
+				`&errors.errorString{s:"2"}, errors.customError{msg:"1"}}, msg:"2"}}, ` +
+				`code:"code"}}, fields:map[string]interface {}{"key":1}}`,
+		},
+		{
+			name:   "ForAFormatterErrorWithV",
+			format: "%v",
+			err:    newQueue(New("1"), formatterError{"2"}),
+			output: "2(v) : 1",
+		},
+		{
+			name:   "ForAFormatterErrorWithPlusV",
+			format: "%+v",
+			err:    &queue{errs: []error{New("1"), formatterError{"2"}}},
+			output: "2(+v) : 1",
+		},
+		{
+			name:   "ForAFormatterErrorWithS",
+			format: "%5s",
+			err:    newQueue(formatterError{"2"}),
+			output: " 2(s)",
+		},
+		{
+			name:   "ForAFormatterErrorWithQ",
+			format: "%q",
+			err:    newQueue(formatterError{"2"}),
+			output: `"2(s)"`,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if output := fmt.Sprintf(tc.format, tc.err); output != tc.output {
+				t.Errorf("fmt.Sprintf(%q, %v) != %q, got %q", tc.format, tc.err, tc.output, output)
+			}
+		})
+	}
+}
+
+// formatterError is an error that formats itself depending on the verb and flags.
+type formatterError struct{ msg string }
+
+func (e formatterError) Error() string { return e.msg }
+
+func (e formatterError) Format(s fmt.State, verb rune) {
+	flags := ""
+	if s.Flag('+') {
+		flags = "+"
+	}
+	_, _ = fmt.Fprintf(s, "%s(%s%c)", e.msg, flags, verb)
+}
+
 // formatterStub is a stub for the stacktrace instance to test error formatting.
 type formatterStub struct{ msg string }
 
 func (fs *formatterStub) Format(s fmt.State, _ rune) { _, _ = s.Write([]byte(fs.msg)) }
+
+func TestUnwrap(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		q    = newQueue(err1, err2)
+	)
+
+	errs := q.Unwrap()
+
+	if len(errs) != 2 || errs[0] != err2 || errs[1] != err1 {
+		t.Errorf("newQueue(%v, %v).Unwrap() must return errors in outer-to-inner order, got %v", err1, err2, errs)
+	}
+}
+
+func TestStdlibIs(t *testing.T) {
+	var (
+		err1 = New("1")
+		err2 = New("2")
+		err3 = New("3")
+	)
+
+	tcs := []struct {
+		name      string
+		err       error
+		targetErr error
+		matched   bool
+	}{
+		{
+			name:      "ForAQueueMember",
+			err:       newQueue(err1, err2),
+			targetErr: err1,
+			matched:   true,
+		},
+		{
+			name:      "ForAMissingError",
+			err:       newQueue(err1, err2),
+			targetErr: err3,
+			matched:   false,
+		},
+		{
+			name:      "ForAMemberWrappedByTheStdlib",
+			err:       newQueue(fmt.Errorf("context: %w", err1), err2),
+			targetErr: err1,
+			matched:   true,
+		},
+		{
+			name:      "ForAQueueWrappedByTheStdlib",
+			err:       fmt.Errorf("context: %w", newQueue(err1, err2)),
+			targetErr: err2,
+			matched:   true,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			matched := stderrors.Is(tc.err, tc.targetErr)
+			fetched := Fetch(tc.err, tc.targetErr) != nil
+
+			if matched != tc.matched {
+				t.Errorf("errors.Is(%v, %v) != %v", tc.err, tc.targetErr, tc.matched)
+			}
+			if matched != fetched {
+				t.Errorf("errors.Is(%v, %v) and Fetch() disagree: %v != %v", tc.err, tc.targetErr, matched, fetched)
+			}
+		})
+	}
+}
+
+func TestStdlibIsForInterleavedWrapping(t *testing.T) {
+	const depth = 32
+
+	err1, errMissing := New("1"), New("missing")
+	err := error(err1)
+	for i := 0; i < depth; i++ {
+		err = fmt.Errorf("layer %d: %w", i, Wrap(err, New("%d", i)))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		if !stderrors.Is(err, err1) || Fetch(err, err1) != err1 {
+			t.Errorf("errors.Is() and Fetch() must find %v in %d interleaved layers", err1, depth)
+		}
+		if stderrors.Is(err, errMissing) || Fetch(err, errMissing) != nil {
+			t.Errorf("errors.Is() and Fetch() mustn't find %v in %d interleaved layers", errMissing, depth)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("errors.Is() must be linear for %d interleaved layers", depth)
+	}
+}
+
+func TestStdlibAs(t *testing.T) {
+	t.Run("ForAValueTarget", func(t *testing.T) {
+		var target customError
+		q := newQueue(New("1"), customError{"2"}, New("3"))
+
+		if !stderrors.As(q, &target) || target.msg != "2" {
+			t.Errorf("errors.As(%v, *customError) must find %v, got %v", q, customError{"2"}, target)
+		}
+	})
+
+	t.Run("ForAPointerMemberAndAValueTarget", func(t *testing.T) {
+		var target customError
+		q := newQueue(New("1"), &customError{"2"})
+
+		if !stderrors.As(q, &target) || target.msg != "2" {
+			t.Errorf("errors.As(%v, *customError) must find %v, got %v", q, customError{"2"}, target)
+		}
+	})
+
+	t.Run("ForAValueMemberAndAPointerTarget", func(t *testing.T) {
+		var target *customError
+		q := newQueue(New("1"), customError{"2"})
+
+		if !stderrors.As(q, &target) || target == nil || target.msg != "2" {
+			t.Errorf("errors.As(%v, **customError) must find %v, got %v", q, customError{"2"}, target)
+		}
+	})
+
+	t.Run("ForAnInterfaceTarget", func(t *testing.T) {
+		var target customErrorInterface
+		q := newQueue(New("1"), customError{"2"})
+
+		if !stderrors.As(q, &target) || target.Message() != "2" {
+			t.Errorf("errors.As(%v, *customErrorInterface) must find %v, got %v", q, customError{"2"}, target)
+		}
+	})
+
+	t.Run("ForAMemberWrappedByTheStdlib", func(t *testing.T) {
+		var target customError
+		var ptrTarget *customError
+		err := Wrap(New("1"), fmt.Errorf("ctx: %w", customError{"2"}))
+
+		if !stderrors.As(err, &target) || target.msg != "2" {
+			t.Errorf("errors.As(%v, *customError) must find %v, got %v", err, customError{"2"}, target)
+		}
+		if !stderrors.As(err, &ptrTarget) || ptrTarget == nil || ptrTarget.msg != "2" {
+			t.Errorf("errors.As(%v, **customError) must find %v, got %v", err, customError{"2"}, ptrTarget)
+		}
+	})
+
+	t.Run("ForAMissingType", func(t *testing.T) {
+		var target customError
+		q := newQueue(New("1"), New("2"))
+
+		if stderrors.As(q, &target) {
+			t.Errorf("errors.As(%v, *customError) must not find anything, got %v", q, target)
+		}
+	})
+}
+
+func TestGetStacktrace(t *testing.T) {
+	var (
+		ownStacktrace   = &formatterStub{"own"}
+		innerStacktrace = &formatterStub{"inner"}
+		otherStacktrace = &formatterStub{"other"}
+	)
+
+	tcs := []struct {
+		name       string
+		errs       []error
+		policy     StackPolicy
+		stacktrace fmt.Formatter
+	}{
+		{
+			name:       "ForErrors",
+			errs:       []error{New("1"), New("2")},
+			stacktrace: ownStacktrace,
+		},
+		{
+			name:       "ForASingleWrappedQueue",
+			errs:       []error{&queue{errs: []error{New("1")}, stacktrace: innerStacktrace}, New("2")},
+			stacktrace: innerStacktrace,
+		},
+		{
+			name: "ForSeveralWrappedQueues",
+			errs: []error{
+				&queue{errs: []error{New("1")}, stacktrace: innerStacktrace},
+				&queue{errs: []error{New("2")}, stacktrace: otherStacktrace},
+			},
+			stacktrace: ownStacktrace,
+		},
+		{
+			name: "ForSeveralWrappedQueuesAndTheKeepInnermostPolicy",
+			errs: []error{
+				&queue{errs: []error{New("1")}, stacktrace: innerStacktrace},
+				&queue{errs: []error{New("2")}, stacktrace: otherStacktrace},
+			},
+			policy:     StackPolicyKeepInnermost,
+			stacktrace: innerStacktrace,
+		},
+		{
+			name: "ForSeveralWrappedQueuesWithoutStacktraces",
+			errs: []error{
+				&queue{errs: []error{New("1")}, stacktrace: innerStacktrace},
+				&queue{errs: []error{New("2")}},
+			},
+			stacktrace: innerStacktrace,
+		},
+		{
+			name: "ForDeeplyWrappedQueues",
+			errs: []error{
+				&queue{errs: []error{&queue{errs: []error{New("1")}, stacktrace: innerStacktrace}}, stacktrace: otherStacktrace},
+			},
+			stacktrace: innerStacktrace,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			q := &queue{errs: tc.errs, stacktrace: ownStacktrace, stackPolicy: tc.policy}
+
+			if s := q.getStacktrace(); s != tc.stacktrace {
+				t.Errorf("getStacktrace() for %v must return %v, got %v", tc.errs, tc.stacktrace, s)
+			}
+		})
+	}
+}
+
+func TestGetStacktraceForTheKeepAllPolicy(t *testing.T) {
+	var (
+		q1 = &queue{errs: []error{New("1")}, stacktrace: &formatterStub{"\ttrace 1\n"}}
+		q2 = &queue{errs: []error{New("2")}, stacktrace: &formatterStub{"\ttrace 2\n"}}
+		q  = &queue{
+			errs:        []error{q1, q2, New("3")},
+			stacktrace:  &formatterStub{"\town trace\n"},
+			stackPolicy: StackPolicyKeepAll,
+		}
+		expectedOutput = "3 : 2 : 1\n2:\n\ttrace 2\n1:\n\ttrace 1\n"
+	)
+
+	if output := fmt.Sprintf("%+v", q); output != expectedOutput {
+		t.Errorf("error output (%%+v format) %q != %q", expectedOutput, output)
+	}
+}
+
+func TestSetStackPolicy(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	prevPolicy := CurrentStackPolicy()
+	defer SetStackPolicy(prevPolicy)
+
+	SetStackPolicy(StackPolicyKeepAll)
+	q1, q2 := Wrap(New("1")), Wrap(New("2"))
+	q := Wrap(q1, q2).(*queue)
+
+	if q.stackPolicy != StackPolicyKeepAll {
+		t.Errorf("Wrap() must keep the stack policy of the moment of creation, got %v", q.stackPolicy)
+	}
+	if ss, ok := q.getStacktrace().(stacktraces); !ok || len(ss) != 2 || ss[0].err != q2 || ss[1].err != q1 {
+		t.Errorf("Wrap() must keep the stacktraces of all wrapped queues, got %v", q.getStacktrace())
+	}
+	if s := StackTrace(q); s == nil || &s[0] != &q1.(*queue).stacktrace.(Stacktrace)[0] {
+		t.Errorf("StackTrace() must return the innermost stacktrace for the keep all policy, got %v", s)
+	}
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultRenderIndent is the indentation of a wrapping level used by Render() by default.
+const defaultRenderIndent = "  "
+
+// errorsPackage is the import path of this package, its frames are skipped when looking for an origin location.
+// nolint:gochecknoglobals
+var errorsPackage = reflect.TypeOf(queue{}).PkgPath()
+
+// RenderOptions configures the verbose rendering of Render().
+type RenderOptions struct {
+	Indent    string // The indentation of a wrapping level, two spaces if empty.
+	Locations bool   // Print the location the nodes were created at, see WrapTrail().
+	Stack     bool   // Print the error stacktrace after the tree.
+}
+
+// Render returns a verbose tree-style representation of the error.
+// Every node of the wrapping tree is printed on its own line indented according to the wrapping depth: error queues
+// with the attached code and fields, the other errors with their Go type, message, code and fields. The stacktrace is
+// the one of the outermost queue wrapped into the error. The same rendering with the locations and the stacktrace is
+// printed with the %+#v verb.
+func Render(err error, opts RenderOptions) string {
+	if isErrNil(err) {
+		return ""
+	}
+	if opts.Indent == "" {
+		opts.Indent = defaultRenderIndent
+	}
+
+	buf := new(bytes.Buffer)
+	Walk(err, func(err error, depth int) bool {
+		if buf.Len() != 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(strings.Repeat(opts.Indent, depth))
+		renderNode(buf, err, opts)
+
+		return true
+	})
+
+	var q *queue
+	if opts.Stack && stderrors.As(err, &q) {
+		if s := q.getStacktrace(); s != nil {
+			buf.WriteString("\n")
+			_, _ = fmt.Fprintf(buf, "%+v", s)
+		}
+	}
+
+	return buf.String()
+}
+
+// renderNode writes a single node line of the wrapping tree.
+func renderNode(buf *bytes.Buffer, err error, opts RenderOptions) {
+	var (
+		code   Code
+		fields map[string]interface{}
+		s      Stacktrace
+		caller uintptr
+	)
+	switch e := err.(type) {
+	case *queue:
+		code, fields, caller = e.code, e.fields, e.caller
+		s, _ = e.stacktrace.(Stacktrace)
+		_, _ = fmt.Fprintf(buf, "%T", e)
+	default:
+		if c, ok := err.(coder); ok {
+			code = c.Code()
+		}
+		if f, ok := err.(fielder); ok {
+			fields = f.Fields()
+		}
+		if p, ok := err.(*PanicError); ok {
+			s = p.Stack
+		}
+		_, _ = fmt.Fprintf(buf, "%T %q", err, err.Error())
+	}
+
+	if code != "" {
+		_, _ = fmt.Fprintf(buf, " code=%s", code)
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(buf, " %s=%v", k, fields[k])
+	}
+
+	if !opts.Locations {
+		return
+	}
+	if caller != 0 {
+		_, _ = fmt.Fprintf(buf, " (%s)", formatLocation(callerFrame(caller)))
+	} else if f, ok := originFrame(s); ok {
+		_, _ = fmt.Fprintf(buf, " (%s)", formatLocation(f))
+	}
+}
+
+// originFrame returns the first frame of the stacktrace that doesn't belong to this package, i.e. the location the
+// error was created at. The frames of the package tests are not skipped.
+func originFrame(s Stacktrace) (Frame, bool) {
+	for _, f := range s.Frames() {
+		if f.Package != errorsPackage || strings.HasSuffix(f.File, "_test.go") {
+			return f, true
+		}
+	}
+
+	return Frame{}, false
+}
+
+// formatLocation returns the frame location in form of file:line.
+func formatLocation(f Frame) string {
+	return sanitizeFilename(f.File, f.Package) + ":" + strconv.Itoa(f.Line)
+}
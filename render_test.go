@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	err1 := New("1")
+
+	tcs := []struct {
+		name   string
+		err    error
+		opts   RenderOptions
+		output string
+	}{
+		{
+			name:   "ForANilError",
+			err:    nil,
+			output: "",
+		},
+		{
+			name:   "ForAPlainError",
+			err:    err1,
+			output: `*errors.errorString "1"`,
+		},
+		{
+			name: "ForAnErrorWrappedByTheStdlib",
+			err:  fmt.Errorf("context: %w", fieldsError{id: 3}),
+			output: `*fmt.wrapError "context: fields error"` + "\n" +
+				`  errors.fieldsError "fields error" id=3`,
+		},
+		{
+			name: "ForAQueue",
+			err:  &queue{errs: []error{err1, NewCoded("coded", "2")}, fields: map[string]interface{}{"b": 2, "a": "1"}},
+			output: `*errors.queue a=1 b=2` + "\n" +
+				`  *errors.codedError "2" code=coded` + "\n" +
+				`  *errors.errorString "1"`,
+		},
+		{
+			name: "ForNestedQueues",
+			err:  &queue{errs: []error{&queue{errs: []error{err1}, code: "code"}, customError{"2"}}},
+			opts: RenderOptions{Indent: "--"},
+			output: `*errors.queue` + "\n" +
+				`--errors.customError "2"` + "\n" +
+				`--*errors.queue code=code` + "\n" +
+				`----*errors.errorString "1"`,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if output := Render(tc.err, tc.opts); output != tc.output {
+				t.Errorf("Render(%v) mismatch\nwant: %s\ngot:  %s", tc.err, tc.output, output)
+			}
+		})
+	}
+}
+
+func TestRenderForLocationsAndStack(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	err := WithMessage(Wrap(New("1")), "message")
+
+	output := Render(err, RenderOptions{Locations: true, Stack: true})
+	expected := regexp.MustCompile(`^\*errors\.queue \(github.com/ameteiko/errors/render_test\.go:\d+\)\n` +
+		`  \*errors\.errorString "message"\n` +
+		`  \*errors\.queue \(github.com/ameteiko/errors/render_test\.go:\d+\)\n` +
+		`    \*errors\.errorString "1"\n` +
+		`\tgithub.com/ameteiko/errors/errors.go:\d+ errors\.Wrap\(\)\n` +
+		`\tgithub.com/ameteiko/errors/render_test.go:\d+ errors\.TestRenderForLocationsAndStack\(\)\n`)
+	if !expected.MatchString(output) {
+		t.Errorf("Render() must print the locations and the stacktrace, got\n%s", output)
+	}
+
+	if verbose := fmt.Sprintf("%+#v", err); verbose != output {
+		t.Errorf("%%+#v must print the same output as Render(), got\n%s", verbose)
+	}
+}
+
+func TestRenderForAPanic(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	output := Render(recoverPanic(nil, "boom"), RenderOptions{Locations: true})
+
+	lines := strings.Split(output, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], `  *errors.PanicError "panic: boom" (`) ||
+		!strings.Contains(lines[1], "panic_test.go:") {
+		t.Errorf("Render() must print the location of the panic, got\n%s", output)
+	}
+}
+
+func TestRenderForAQueueWrappedByTheStdlib(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	output := Render(fmt.Errorf("context: %w", Wrap(New("1"))), RenderOptions{Stack: true})
+
+	lines := strings.Split(output, "\n")
+	if len(lines) < 4 || lines[0] != `*fmt.wrapError "context: 1"` ||
+		!strings.HasPrefix(lines[3], "\tgithub.com/ameteiko/errors/errors.go:") {
+		t.Errorf("Render() must print the stacktrace of the wrapped queue, got\n%s", output)
+	}
+}
@@ -0,0 +1,198 @@
+package errors
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// retryClass is the retry classification attached with MarkRetryable() or MarkPermanent().
+type retryClass int8
+
+const (
+	retryUnknown retryClass = iota
+	retryRetryable
+	retryPermanent
+)
+
+// DefaultRetryPolicy is the policy Retry() takes the zero fields of the provided one from, besides Jitter.
+// nolint:gochecknoglobals
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// RetryPolicy defines the number of attempts and the exponential backoff between them.
+type RetryPolicy struct {
+	MaxAttempts  int           // The maximum number of attempts including the first one.
+	InitialDelay time.Duration // The delay before the second attempt.
+	MaxDelay     time.Duration // The upper bound of the delay.
+	Multiplier   float64       // The factor the delay grows by with every attempt.
+	Jitter       float64       // The fraction of the delay, from 0 to 1, the delay is randomly reduced by, 0 disables it.
+}
+
+// MarkRetryable returns an error classified as retryable.
+// The classification doesn't affect the error message and is checked with IsRetryable().
+func MarkRetryable(err error) error {
+	if isErrNil(err) {
+		return nil
+	}
+
+	return markRetry(newQueue(err), retryRetryable, 0)
+}
+
+// MarkPermanent returns an error classified as permanent, so it is not retried even if it wraps a retryable error.
+func MarkPermanent(err error) error {
+	if isErrNil(err) {
+		return nil
+	}
+
+	return markRetry(newQueue(err), retryPermanent, 0)
+}
+
+// WithRetryAfter returns an error classified as retryable not earlier than after the duration d.
+// The duration is looked up with RetryAfter(), Retry() doesn't retry the error earlier.
+func WithRetryAfter(err error, d time.Duration) error {
+	if isErrNil(err) {
+		return nil
+	}
+
+	return markRetry(newQueue(err), retryRetryable, d)
+}
+
+// markRetry attaches the retry classification to the queue.
+func markRetry(q *queue, class retryClass, after time.Duration) error {
+	q.retry = class
+	q.retryAfter = after
+
+	return q
+}
+
+// IsRetryable reports whether the error is worth retrying.
+// The outermost classification wins: the one attached with MarkRetryable(), WithRetryAfter() or MarkPermanent(), a
+// timeout error (e.g. net.Error with Timeout() returning true) or context.DeadlineExceeded. Unclassified errors are
+// not retryable.
+func IsRetryable(err error) bool {
+	class := retryUnknown
+	Walk(err, func(err error, _ int) bool {
+		class = errRetryClass(err)
+
+		return class == retryUnknown
+	})
+
+	return class == retryRetryable
+}
+
+// RetryAfter returns the outermost retry-after duration attached to the error with WithRetryAfter().
+func RetryAfter(err error) (d time.Duration, ok bool) {
+	Walk(err, func(err error, _ int) bool {
+		if q, isQueue := err.(*queue); isQueue && q.retryAfter > 0 {
+			d, ok = q.retryAfter, true
+		}
+
+		return !ok
+	})
+
+	return d, ok
+}
+
+// errRetryClass returns the retry classification of a single error without inspecting the wrapped ones.
+func errRetryClass(err error) retryClass {
+	if q, ok := err.(*queue); ok {
+		return q.retry
+	}
+	if err == context.DeadlineExceeded {
+		return retryRetryable
+	}
+	if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+		return retryRetryable
+	}
+
+	return retryUnknown
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable in terms of IsRetryable() or the policy
+// attempts are exhausted.
+// The delay between attempts grows exponentially and is reduced by a random jitter, it is never shorter than the
+// duration attached with WithRetryAfter(). The zero fields of the policy, besides Jitter, are taken from
+// DefaultRetryPolicy. The returned error wraps the errors of all attempts, the last one is the outermost. If ctx is
+// done while waiting for the next attempt, the context error is the outermost one.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+
+	q := newQueue()
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if isErrNil(err) {
+			return nil
+		}
+
+		q.errs = append(q.errs, err)
+		if attempt >= policy.MaxAttempts || !IsRetryable(err) {
+			return q
+		}
+
+		delay := policy.delay(attempt)
+		if d, ok := RetryAfter(err); ok && d > delay {
+			delay = d
+		}
+		if err := sleep(ctx, delay); err != nil {
+			q.errs = append(q.errs, err)
+			return q
+		}
+	}
+}
+
+// withDefaults returns the policy which zero fields are taken from DefaultRetryPolicy and the jitter is within [0, 1].
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = DefaultRetryPolicy.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+
+	return p
+}
+
+// delay returns the delay after the attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay)
+	for i := 1; i < attempt && d < float64(p.MaxDelay); i++ {
+		d *= p.Multiplier
+	}
+	if d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+
+	// nolint:gosec
+	return time.Duration(d - d*p.Jitter*rand.Float64())
+}
+
+// sleep waits for the duration d and returns the context error if ctx is done earlier.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,266 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// timeoutError is a net.Error stub.
+type timeoutError struct {
+	timeout bool
+}
+
+func (e timeoutError) Error() string   { return "i/o timeout" }
+func (e timeoutError) Timeout() bool   { return e.timeout }
+func (e timeoutError) Temporary() bool { return e.timeout }
+
+var _ net.Error = timeoutError{}
+
+func TestIsRetryable(t *testing.T) {
+	err1 := New("1")
+
+	tcs := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{
+			name:      "ForANilError",
+			err:       nil,
+			retryable: false,
+		},
+		{
+			name:      "ForAnUnclassifiedError",
+			err:       Wrap(err1),
+			retryable: false,
+		},
+		{
+			name:      "ForARetryableError",
+			err:       MarkRetryable(err1),
+			retryable: true,
+		},
+		{
+			name:      "ForAWrappedRetryableError",
+			err:       WithMessage(MarkRetryable(err1), "message"),
+			retryable: true,
+		},
+		{
+			name:      "ForAPermanentError",
+			err:       MarkPermanent(err1),
+			retryable: false,
+		},
+		{
+			name:      "ForAPermanentErrorWrappingARetryableOne",
+			err:       MarkPermanent(MarkRetryable(err1)),
+			retryable: false,
+		},
+		{
+			name:      "ForARetryableErrorWrappingAPermanentOne",
+			err:       MarkRetryable(MarkPermanent(err1)),
+			retryable: true,
+		},
+		{
+			name:      "ForAnErrorWithRetryAfter",
+			err:       WithRetryAfter(err1, time.Second),
+			retryable: true,
+		},
+		{
+			name:      "ForATimeoutError",
+			err:       Wrap(timeoutError{timeout: true}, err1),
+			retryable: true,
+		},
+		{
+			name:      "ForANotTimeoutNetError",
+			err:       Wrap(timeoutError{timeout: false}),
+			retryable: false,
+		},
+		{
+			name:      "ForAPermanentTimeoutError",
+			err:       MarkPermanent(timeoutError{timeout: true}),
+			retryable: false,
+		},
+		{
+			name:      "ForTheDeadlineExceededError",
+			err:       Wrap(fmt.Errorf("request: %w", context.DeadlineExceeded)),
+			retryable: true,
+		},
+		{
+			name:      "ForTheCanceledError",
+			err:       Wrap(context.Canceled),
+			retryable: false,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if retryable := IsRetryable(tc.err); retryable != tc.retryable {
+				t.Errorf("IsRetryable(%v) != %t", tc.err, tc.retryable)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err1 := New("1")
+
+	tcs := []struct {
+		name string
+		err  error
+		d    time.Duration
+		ok   bool
+	}{
+		{
+			name: "ForANilError",
+			err:  nil,
+		},
+		{
+			name: "ForARetryableError",
+			err:  MarkRetryable(err1),
+		},
+		{
+			name: "ForAnErrorWithRetryAfter",
+			err:  Wrap(WithRetryAfter(err1, time.Second)),
+			d:    time.Second,
+			ok:   true,
+		},
+		{
+			name: "ForSeveralRetryAfterDurations",
+			err:  WithRetryAfter(WithRetryAfter(err1, time.Second), time.Minute),
+			d:    time.Minute,
+			ok:   true,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			d, ok := RetryAfter(tc.err)
+			if d != tc.d || ok != tc.ok {
+				t.Errorf("RetryAfter(%v) != (%v, %t), got (%v, %t)", tc.err, tc.d, tc.ok, d, ok)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errRetryable, errPermanent := MarkRetryable(New("retryable")), MarkPermanent(New("permanent"))
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	tcs := []struct {
+		name     string
+		errs     []error
+		attempts int
+		msg      string
+	}{
+		{
+			name:     "ForASucceededAttempt",
+			errs:     []error{nil},
+			attempts: 1,
+			msg:      "",
+		},
+		{
+			name:     "ForATypedNilError",
+			errs:     []error{(*customError)(nil)},
+			attempts: 1,
+			msg:      "",
+		},
+		{
+			name:     "ForASucceededRetry",
+			errs:     []error{errRetryable, nil},
+			attempts: 2,
+			msg:      "",
+		},
+		{
+			name:     "ForAnUnclassifiedError",
+			errs:     []error{New("1")},
+			attempts: 1,
+			msg:      "1",
+		},
+		{
+			name:     "ForAPermanentError",
+			errs:     []error{errRetryable, errPermanent},
+			attempts: 2,
+			msg:      "permanent : retryable",
+		},
+		{
+			name:     "ForExhaustedAttempts",
+			errs:     []error{errRetryable, errRetryable, errRetryable, nil},
+			attempts: 3,
+			msg:      "retryable : retryable : retryable",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), policy, func(context.Context) error {
+				attempts++
+				return tc.errs[attempts-1]
+			})
+
+			if attempts != tc.attempts {
+				t.Errorf("Retry() must make %d attempts, made %d", tc.attempts, attempts)
+			}
+			if tc.msg == "" && err != nil || tc.msg != "" && (err == nil || err.Error() != tc.msg) {
+				t.Errorf("Retry() error message mismatch, %q != %v", tc.msg, err)
+			}
+		})
+	}
+}
+
+func TestRetryForADoneContext(t *testing.T) {
+	err1 := New("1")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 3}, func(context.Context) error {
+		attempts++
+		return WithRetryAfter(err1, time.Hour)
+	})
+
+	if attempts != 1 {
+		t.Errorf("Retry() must wait for the retry-after duration, made %d attempts", attempts)
+	}
+	if Fetch(err, context.DeadlineExceeded) == nil || Fetch(err, err1) == nil {
+		t.Errorf("Retry() must return the attempt errors and the context error, got %v", err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+
+	for attempt, d := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if delay := policy.delay(attempt + 1); delay != d {
+			t.Errorf("delay(%d) != %v, got %v", attempt+1, d, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := policy.delay(1); delay <= time.Second/2 || delay > time.Second {
+			t.Fatalf("delay(1) must be within (0.5s, 1s] with the jitter, got %v", delay)
+		}
+	}
+}
+
+func TestStackModeForMarkRetryable(t *testing.T) {
+	setStackConfig(t, StackCaller, 16)
+
+	for name, err := range map[string]error{
+		"MarkRetryable":  MarkRetryable(New("1")),
+		"MarkPermanent":  MarkPermanent(New("1")),
+		"WithRetryAfter": WithRetryAfter(New("1"), time.Second),
+	} {
+		s, _ := err.(*queue).stacktrace.(Stacktrace)
+		if frames := s.Frames(); len(frames) != 1 || !strings.HasSuffix(frames[0].Function, "TestStackModeForMarkRetryable") {
+			t.Errorf("%s() in the caller mode must capture the caller, got %v", name, frames)
+		}
+	}
+}
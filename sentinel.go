@@ -0,0 +1,14 @@
+package errors
+
+// Sentinel is an immutable error that can be declared as a constant:
+//
+//	const ErrNotFound = errors.Sentinel("not found")
+//
+// Sentinels are compared by value, so Fetch() matches a sentinel by identity even if it is reconstructed from its
+// text, e.g. after deserialization, while an error of another type with the same message doesn't match.
+type Sentinel string
+
+// Error returns an error message.
+func (s Sentinel) Error() string {
+	return string(s)
+}
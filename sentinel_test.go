@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
+
+const (
+	errSentinelNotFound  = Sentinel("not found")
+	errSentinelForbidden = Sentinel("forbidden")
+)
+
+func TestFetchForSentinels(t *testing.T) {
+	tcs := []struct {
+		name      string
+		err       error
+		targetErr error
+		resErr    error
+	}{
+		{
+			name:      "ForAWrappedSentinel",
+			err:       Wrap(New("1"), errSentinelNotFound),
+			targetErr: errSentinelNotFound,
+			resErr:    errSentinelNotFound,
+		},
+		{
+			name:      "ForAReconstructedSentinel",
+			err:       WithMessage(errSentinelNotFound, "message"),
+			targetErr: Sentinel("not found"),
+			resErr:    errSentinelNotFound,
+		},
+		{
+			name:      "ForASentinelWrappedByTheStandardLibrary",
+			err:       Wrap(fmt.Errorf("user: %w", errSentinelNotFound)),
+			targetErr: errSentinelNotFound,
+			resErr:    errSentinelNotFound,
+		},
+		{
+			name:      "ForAnotherSentinel",
+			err:       Wrap(errSentinelForbidden),
+			targetErr: errSentinelNotFound,
+			resErr:    nil,
+		},
+		{
+			name:      "ForAnErrorWithTheSameMessage",
+			err:       Wrap(New("not found")),
+			targetErr: errSentinelNotFound,
+			resErr:    nil,
+		},
+		{
+			name:      "ForASentinelAndAnErrorWithTheSameMessage",
+			err:       Wrap(errSentinelNotFound),
+			targetErr: stderrors.New("not found"),
+			resErr:    nil,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			if resErr := Fetch(tc.err, tc.targetErr); resErr != tc.resErr {
+				t.Errorf("Fetch(%v, %v) != %v, got %v", tc.err, tc.targetErr, tc.resErr, resErr)
+			}
+			if is := stderrors.Is(tc.err, tc.targetErr); is != (tc.resErr != nil) {
+				t.Errorf("errors.Is(%v, %v) != %t", tc.err, tc.targetErr, tc.resErr != nil)
+			}
+		})
+	}
+}
+
+func TestFetchForADeserializedSentinel(t *testing.T) {
+	data, err := json.Marshal(Wrap(errSentinelNotFound))
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	var doc jsonQueue
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+
+	var errs []error
+	for _, e := range doc.Errors {
+		errs = append(errs, Sentinel(e.Message))
+	}
+	if Fetch(Wrap(errs...), errSentinelNotFound) != errSentinelNotFound {
+		t.Errorf("Fetch() must match a sentinel reconstructed from %s", data)
+	}
+}
@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// LogValue returns the queue representation for the log/slog package.
+// The group contains the error message, the messages and Go types of the queue errors in the outer-to-inner order, the
+// error code, the attached fields and the error origin stacktrace.
+func (q *queue) LogValue() slog.Value {
+	return errLogValue(q, q)
+}
+
+// NewSlogHandler returns a slog.Handler that expands errors containing an error queue in record attributes before
+// passing records to h. It is useful for the handlers that don't resolve slog.LogValuer values on their own and for
+// the errors that wrap queues by means of the standard library.
+func NewSlogHandler(h slog.Handler) slog.Handler {
+	return &slogHandler{h: h}
+}
+
+// slogHandler is a slog.Handler expanding error queues.
+type slogHandler struct {
+	h slog.Handler
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle expands record attributes and passes the record to the wrapped handler.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	expanded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		expanded.AddAttrs(expandSlogAttr(a))
+		return true
+	})
+
+	return h.h.Handle(ctx, expanded)
+}
+
+// WithAttrs returns a handler with expanded attributes.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = expandSlogAttr(a)
+	}
+
+	return &slogHandler{h: h.h.WithAttrs(expanded)}
+}
+
+// WithGroup returns a handler with the group.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{h: h.h.WithGroup(name)}
+}
+
+// expandSlogAttr replaces errors containing an error queue with their log representation.
+func expandSlogAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		expanded := make([]slog.Attr, len(attrs))
+		for i, groupAttr := range attrs {
+			expanded[i] = expandSlogAttr(groupAttr)
+		}
+
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+	case slog.KindAny, slog.KindLogValuer:
+		err, ok := a.Value.Any().(error)
+		if !ok {
+			return a
+		}
+
+		var q *queue
+		if stderrors.As(err, &q) {
+			return slog.Attr{Key: a.Key, Value: errLogValue(err, q)}
+		}
+	}
+
+	return a
+}
+
+// errLogValue returns the log representation of err containing the q queue.
+func errLogValue(err error, q *queue) slog.Value {
+	errs := collectErrors(err)
+	msgs := make([]string, len(errs))
+	types := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+		types[i] = fmt.Sprintf("%T", e)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("message", err.Error()),
+		slog.Any("chain", msgs),
+		slog.Any("types", types),
+	}
+	if code := CodeOf(err); code != "" {
+		attrs = append(attrs, slog.String("code", string(code)))
+	}
+	if fields := Fields(err); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fieldAttrs := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			fieldAttrs[i] = slog.Any(k, fields[k])
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fieldAttrs...)})
+	}
+	if s := originStacktrace(q.getStacktrace()); s != nil {
+		attrs = append(attrs, slog.Any("stack", CurrentFrameFilter().lines(s.Frames())))
+	}
+
+	return slog.GroupValue(attrs...)
+}
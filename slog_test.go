@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// logRecord logs the attribute with the handler and returns the decoded "err" attribute.
+func logRecord(t *testing.T, newHandler func(h slog.Handler) slog.Handler, attr slog.Attr) map[string]interface{} {
+	t.Helper()
+
+	var (
+		buf    = new(bytes.Buffer)
+		record map[string]interface{}
+	)
+	slog.New(newHandler(slog.NewJSONHandler(buf, nil))).Error("failed", attr)
+
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid log record %s: %v", buf, err)
+	}
+	errAttr, ok := record["err"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("the err attribute must be a group, got %s", buf)
+	}
+
+	return errAttr
+}
+
+func TestLogValue(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	var (
+		err1 = New("1")
+		q    = WithFields(WithCode(WithMessage(err1, "message"), "code"), "user_id", 42, "request_id", "abc")
+	)
+
+	errAttr := logRecord(t, func(h slog.Handler) slog.Handler { return h }, slog.Any("err", q))
+
+	if errAttr["message"] != "message : 1" {
+		t.Errorf("log value message mismatch, %q != %q", "message : 1", errAttr["message"])
+	}
+	if chain := errAttr["chain"]; !reflect.DeepEqual(chain, []interface{}{"message", "1"}) {
+		t.Errorf("log value chain mismatch, %v != %v", []string{"message", "1"}, chain)
+	}
+	types := []interface{}{"*errors.errorString", "*errors.errorString"}
+	if !reflect.DeepEqual(errAttr["types"], types) {
+		t.Errorf("log value types mismatch, %v != %v", types, errAttr["types"])
+	}
+	if errAttr["code"] != "code" {
+		t.Errorf("log value code mismatch, %q != %q", "code", errAttr["code"])
+	}
+	fields := map[string]interface{}{"user_id": float64(42), "request_id": "abc"}
+	if !reflect.DeepEqual(errAttr["fields"], fields) {
+		t.Errorf("log value fields mismatch, %v != %v", fields, errAttr["fields"])
+	}
+	if stack := fmt.Sprint(errAttr["stack"]); !strings.Contains(stack, "slog_test.go") {
+		t.Errorf("log value must contain the error stacktrace, got %v", errAttr["stack"])
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	q := Wrap(New("1"), New("2"))
+
+	tcs := []struct {
+		name string
+		attr slog.Attr
+		msg  string
+	}{
+		{
+			name: "ForAQueue",
+			attr: slog.Any("err", q),
+			msg:  "2 : 1",
+		},
+		{
+			name: "ForAQueueWrappedByTheStdlib",
+			attr: slog.Any("err", fmt.Errorf("context: %w", q)),
+			msg:  "context: 2 : 1",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			errAttr := logRecord(t, NewSlogHandler, tc.attr)
+
+			if errAttr["message"] != tc.msg {
+				t.Errorf("log value message mismatch, %q != %q", tc.msg, errAttr["message"])
+			}
+			if _, ok := errAttr["stack"]; !ok {
+				t.Errorf("log value must contain the error stacktrace, got %v", errAttr)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerForGroupsAndHandlerAttrs(t *testing.T) {
+	var (
+		buf    = new(bytes.Buffer)
+		q      = Wrap(New("1"))
+		record map[string]interface{}
+	)
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil))).
+		With(slog.Any("cause", fmt.Errorf("context: %w", q))).
+		WithGroup("request")
+
+	logger.Error("failed", slog.Group("db", slog.Any("err", fmt.Errorf("db: %w", q))))
+
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid log record %s: %v", buf, err)
+	}
+
+	cause, _ := record["cause"].(map[string]interface{})
+	if cause["message"] != "context: 1" {
+		t.Errorf("handler attributes must be expanded, got %s", buf)
+	}
+	request, _ := record["request"].(map[string]interface{})
+	db, _ := request["db"].(map[string]interface{})
+	dbErr, _ := db["err"].(map[string]interface{})
+	if dbErr["message"] != "db: 1" {
+		t.Errorf("group attributes must be expanded, got %s", buf)
+	}
+}
@@ -2,15 +2,32 @@ package errors
 
 import (
 	"bytes"
+	stderrors "errors"
 	"fmt"
 	"go/build"
+	"os"
+	"path"
+	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const (
 	stacktraceDepth = 16
+
+	// stackModeEnv is the environment variable setting the initial stack mode: full, caller or off.
+	stackModeEnv = "ERRORS_STACK_MODE"
+	// stackDepthEnv is the environment variable setting the initial stacktrace depth.
+	stackDepthEnv = "ERRORS_STACK_DEPTH"
+
+	// modCacheDir is the path element of the module cache directory.
+	modCacheDir = "/pkg/mod/"
+	// vendorDir is the path element of the vendor directory.
+	vendorDir = "/vendor/"
 )
 
 var (
@@ -18,63 +35,284 @@ var (
 	gopath = build.Default.GOPATH + "/src/"
 	// nolint:gochecknoglobals
 	goroot = runtime.GOROOT() + "/src/"
+
+	// nolint:gochecknoglobals
+	modules = readBuildModules()
+	// nolint:gochecknoglobals
+	mainModuleDir atomic.Pointer[string]
+
+	// nolint:gochecknoglobals
+	pathRulesMu sync.RWMutex
+	// nolint:gochecknoglobals
+	pathRules []PathRule
+
+	// nolint:gochecknoglobals
+	stackMode atomic.Int32
+	// nolint:gochecknoglobals
+	stackDepth atomic.Int32
+	// nolint:gochecknoglobals
+	stackPolicy atomic.Int32
+)
+
+// nolint:gochecknoinits
+func init() {
+	mode, ok := parseStackMode(os.Getenv(stackModeEnv))
+	if !ok {
+		mode = StackFull
+	}
+	SetStackMode(mode)
+
+	depth, err := strconv.Atoi(os.Getenv(stackDepthEnv))
+	if err != nil {
+		depth = stacktraceDepth
+	}
+	SetStackDepth(depth)
+}
+
+// StackMode defines how stacktraces are captured on error queue creation.
+type StackMode int32
+
+const (
+	// StackFull captures up to the stack depth frames (see SetStackDepth()). It is the default mode.
+	StackFull StackMode = iota
+	// StackCaller captures the caller of the Wrap(), WithMessage(), etc. function only.
+	StackCaller
+	// StackOff disables capturing stacktraces.
+	StackOff
+)
+
+// String returns the stack mode name as accepted by the ERRORS_STACK_MODE environment variable.
+func (m StackMode) String() string {
+	switch m {
+	case StackFull:
+		return "full"
+	case StackCaller:
+		return "caller"
+	case StackOff:
+		return "off"
+	}
+
+	return "StackMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// parseStackMode returns the stack mode by its name.
+func parseStackMode(name string) (StackMode, bool) {
+	for _, m := range []StackMode{StackFull, StackCaller, StackOff} {
+		if strings.EqualFold(name, m.String()) {
+			return m, true
+		}
+	}
+
+	return StackFull, false
+}
+
+// SetStackMode sets the way stacktraces are captured.
+// The initial mode is taken from the ERRORS_STACK_MODE environment variable (full, caller or off), StackFull is used
+// by default. It is safe to change the mode at runtime, it affects the errors created afterwards.
+func SetStackMode(m StackMode) {
+	stackMode.Store(int32(m))
+}
+
+// CurrentStackMode returns the current stack mode.
+func CurrentStackMode() StackMode {
+	return StackMode(stackMode.Load())
+}
+
+// SetStackDepth sets the maximum number of frames captured in the StackFull mode.
+// The initial depth is taken from the ERRORS_STACK_DEPTH environment variable, 16 is used by default. Values less than
+// 1 are treated as 1. It is safe to change the depth at runtime, it affects the errors created afterwards.
+func SetStackDepth(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+	stackDepth.Store(int32(depth))
+}
+
+// CurrentStackDepth returns the current stack depth.
+func CurrentStackDepth() int {
+	return int(stackDepth.Load())
+}
+
+// StackPolicy defines the stacktrace of an error wrapping several queues that have stacktraces.
+type StackPolicy int32
+
+const (
+	// StackPolicyCaptureNew uses the stacktrace captured by Wrap(). It is the default policy.
+	StackPolicyCaptureNew StackPolicy = iota
+	// StackPolicyKeepInnermost uses the stacktrace of the innermost wrapped queue (the first Wrap() argument).
+	StackPolicyKeepInnermost
+	// StackPolicyKeepAll keeps the stacktraces of all wrapped queues, %+v prints each of them labelled with the error
+	// it belongs to.
+	StackPolicyKeepAll
 )
 
+// SetStackPolicy sets the stack policy for wrapping several queues that have stacktraces.
+// It is safe to change the policy at runtime, it affects the errors created afterwards.
+func SetStackPolicy(p StackPolicy) {
+	stackPolicy.Store(int32(p))
+}
+
+// CurrentStackPolicy returns the current stack policy.
+func CurrentStackPolicy() StackPolicy {
+	return StackPolicy(stackPolicy.Load())
+}
+
 // stacktrace wraps a stacktrace of program counters.
 type Stacktrace []uintptr
 
-// newStacktrace returns a stacktrace.
-func newStacktrace() Stacktrace {
-	s := make(Stacktrace, stacktraceDepth)
-	// Skipping 3 runtime callers:
+// newStacktrace returns a stacktrace according to the current stack mode and the program counter of the function
+// that called the exported one creating the queue. The caller is taken from the stacktrace, so it is captured
+// separately only if the stack depth is 1. Returns nil and 0 in the StackOff mode.
+func newStacktrace() (Stacktrace, uintptr) {
+	// Skipping 3 runtime callers, so the stacktrace starts with the function that created the queue:
 	//   0 - runtime.Callers()
-	//   1 - stacktrace.New()
+	//   1 - errors.newStacktrace()
 	//   2 - errors.newQueue()
 	//   3 - errors.Wrap()
-	n := runtime.Callers(3, s)
+	//   4 - errors.Wrap() caller
+	switch CurrentStackMode() {
+	case StackOff:
+		return nil, 0
+	case StackCaller:
+		s := make(Stacktrace, 1)
+		if runtime.Callers(4, s) == 0 {
+			return nil, 0
+		}
+
+		return s, s[0]
+	default:
+		s := make(Stacktrace, CurrentStackDepth())
+		n := runtime.Callers(3, s)
+		if n > 1 {
+			return s[0:n], s[1]
+		}
+
+		var pc [1]uintptr
+		runtime.Callers(4, pc[:])
+
+		return s[0:n], pc[0]
+	}
+}
+
+// callerFrame returns the frame of the program counter captured by newStacktrace().
+func callerFrame(pc uintptr) Frame {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
 
-	return s[0:n]
+	return Frame{File: f.File, Line: f.Line, Function: f.Function, Package: funcPackage(f.Function)}
 }
 
 // Format prints the stacktrace.
+// Frames are filtered according to the current frame filter, see SetFrameFilter().
 func (s Stacktrace) Format(st fmt.State, _ rune) {
 	b := new(bytes.Buffer)
+	for _, line := range CurrentFrameFilter().lines(s.Frames()) {
+		b.WriteString("\t")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	// nolint
+	_, _ = b.WriteTo(st)
+}
+
+// formatFrame returns the text representation of the frame.
+func formatFrame(f Frame) string {
+	return sanitizeFilename(f.File, f.Package) + ":" + strconv.Itoa(f.Line) + " " + sanitizeFuncName(f.Function)
+}
+
+// Frame is a single stacktrace entry.
+type Frame struct {
+	File     string // Full path of the source file, e.g. /home/user/errors/errors.go.
+	Line     int    // Line number in the source file.
+	Function string // Fully qualified function name, e.g. github.com/ameteiko/errors.Wrap.
+	Package  string // Import path of the function package, e.g. github.com/ameteiko/errors.
+}
+
+// StackTrace returns the stacktrace describing the error origin.
+// err is supposed to be a queue instance, but it can also be any error wrapping a queue. If the error keeps several
+// stacktraces (see StackPolicyKeepAll), the one of the innermost wrapped queue is returned. Returns nil if there is no
+// stacktrace.
+func StackTrace(err error) Stacktrace {
+	var q *queue
+	if isErrNil(err) || !stderrors.As(err, &q) {
+		return nil
+	}
+
+	return originStacktrace(q.getStacktrace())
+}
+
+// originStacktrace returns the stacktrace of the error origin.
+// In case of several stacktraces kept, it is the one of the innermost wrapped queue.
+func originStacktrace(f fmt.Formatter) Stacktrace {
+	switch s := f.(type) {
+	case Stacktrace:
+		return s
+	case stacktraces:
+		return originStacktrace(s[len(s)-1].stacktrace)
+	}
+
+	return nil
+}
+
+// labelledStacktrace is a stacktrace of the wrapped error.
+type labelledStacktrace struct {
+	err        error
+	stacktrace fmt.Formatter
+}
+
+// stacktraces are the stacktraces of several wrapped queues in the outer-to-inner order.
+type stacktraces []labelledStacktrace
+
+// Format prints every stacktrace preceded by the message of the error it belongs to.
+func (ss stacktraces) Format(st fmt.State, verb rune) {
+	for _, s := range ss {
+		_, _ = st.Write([]byte(s.err.Error() + ":\n"))
+		s.stacktrace.Format(st, verb)
+	}
+}
+
+// Frames returns the stacktrace entries up to the frame the current frame filter stops at, see SetFrameFilter().
+// By default, nothing beyond the main.main() function is returned.
+func (s Stacktrace) Frames() (frames []Frame) {
+	if len(s) == 0 {
+		return nil
+	}
+
+	stop := CurrentFrameFilter().stop()
 	ff := runtime.CallersFrames([]uintptr(s))
-	var mainProcessed bool
 	for {
 		f, more := ff.Next()
-		file := sanitizeFilename(f.File)
-		line := strconv.Itoa(f.Line)
-		fun := sanitizeFuncName(f.Function)
+		frame := Frame{File: f.File, Line: f.Line, Function: f.Function, Package: funcPackage(f.Function)}
+		frames = append(frames, frame)
 
-		// Don't print anything beyond main.main.
-		if mainProcessed && !strings.HasPrefix(fun, "main.") {
+		if !more || stop(frame) {
 			break
 		}
-		if !mainProcessed && fun == "main.main()" {
-			mainProcessed = true
-		}
+	}
 
-		b.WriteString("\t")
-		b.WriteString(file)
-		b.WriteString(":")
-		b.WriteString(line)
-		b.WriteString(" ")
-		b.WriteString(fun)
-		b.WriteString("\n")
+	return frames
+}
 
-		if !more {
-			break
-		}
+// funcPackage returns the package import path of the fully qualified function name.
+// Dots in the last path element are escaped by the linker, so they are unescaped back.
+// Transforms:
+//
+//	github.com/ameteiko/errors.(*queue).Error -> github.com/ameteiko/errors
+//	gopkg.in/yaml%2ev3.Unmarshal -> gopkg.in/yaml.v3
+func funcPackage(n string) string {
+	lastSlashIndex := strings.LastIndex(n, "/")
+	dotIndex := strings.Index(n[lastSlashIndex+1:], ".")
+	if dotIndex < 0 {
+		return ""
 	}
 
-	// nolint
-	_, _ = b.WriteTo(st)
+	return strings.ReplaceAll(n[:lastSlashIndex+1+dotIndex], "%2e", ".")
 }
 
 // sanitizeFuncName trims fully qualified module path from the function name.
 // Transforms:
-//     github.com/ameteiko/errors/stacktrace.New -> stacktrace.New()
+//
+//	github.com/ameteiko/errors/stacktrace.New -> stacktrace.New()
 func sanitizeFuncName(n string) string {
 	if n == "" {
 		return "unknown"
@@ -84,12 +322,235 @@ func sanitizeFuncName(n string) string {
 	return n[lastSlashIndex+1:] + "()"
 }
 
-// sanitizeFilename trims GOROOT and GOPATH prefixes from the fully qualified file name.
+// sanitizeFilename trims environment specific prefixes from the fully qualified file name.
+// The rules are applied in the following order, the first applicable one wins:
+//   - path rules set with SetPathRules();
+//   - module cache prefixes: /home/ci/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go ->
+//     github.com/pkg/errors@v0.9.1/errors.go;
+//   - GOROOT prefix: /usr/local/go/src/net/http/server.go -> net/http/server.go;
+//   - the packages of the main module and of the modules replaced with local directories are transformed regardless
+//     of the directory they are built from: /home/ci/errors/errors.go -> github.com/ameteiko/errors/errors.go, the
+//     main package is identified by its import path;
+//   - vendor directories, the module version is taken from the build information:
+//     /home/ci/app/vendor/github.com/pkg/errors/errors.go -> github.com/pkg/errors@v0.9.1/errors.go;
+//   - the main module directory, once it is known from a frame of a main module package, and the directories of the
+//     modules replaced with local directories: /home/ci/errors/internal/gen.go ->
+//     github.com/ameteiko/errors/internal/gen.go;
+//   - the directory of the package pkg: /home/ci/src/github.com/ameteiko/errors/errors.go ->
+//     github.com/ameteiko/errors/errors.go;
+//   - GOPATH prefix: /Users/ameteiko/Projects/go/src/github.com/ameteiko/errors/errors.go ->
+//     github.com/ameteiko/errors/errors.go.
+func sanitizeFilename(n, pkg string) string {
+	if rewritten, ok := applyPathRules(n); ok {
+		return rewritten
+	}
+
+	if i := strings.LastIndex(n, modCacheDir); i >= 0 {
+		return unescapeModulePath(n[i+len(modCacheDir):])
+	}
+
+	if strings.HasPrefix(n, goroot) {
+		return strings.TrimPrefix(n, goroot)
+	}
+
+	if pkg == "main" && modules.mainPkg != "" {
+		pkg = modules.mainPkg
+	}
+	dir, file := path.Split(n)
+	if modules.isMainPackage(pkg) {
+		learnMainModuleDir(dir, pkg)
+		return pkg + "/" + file
+	}
+	if modules.isReplacedPackage(pkg) {
+		return pkg + "/" + file
+	}
+
+	if rewritten, ok := modules.trimVendorDir(n); ok {
+		return rewritten
+	}
+
+	if rewritten, ok := modules.trimModuleDir(n); ok {
+		return rewritten
+	}
+
+	if pkg != "" && strings.HasSuffix(dir, "/"+pkg+"/") {
+		return pkg + "/" + file
+	}
+
+	return strings.TrimPrefix(n, gopath)
+}
+
+// PathRule rewrites the file paths of stacktrace frames starting with Prefix: the prefix gets replaced with
+// Replacement.
+type PathRule struct {
+	Prefix      string
+	Replacement string
+}
+
+// SetPathRules sets the rules rewriting the file paths of stacktrace frames.
+// The rules are checked in order, the first one matching the file path is applied and no other trimming is done for
+// the path. It is safe to change the rules at runtime.
+func SetPathRules(rules ...PathRule) {
+	pathRulesMu.Lock()
+	defer pathRulesMu.Unlock()
+
+	pathRules = append([]PathRule(nil), rules...)
+}
+
+// applyPathRules rewrites the file path with the first matching path rule.
+func applyPathRules(n string) (string, bool) {
+	pathRulesMu.RLock()
+	defer pathRulesMu.RUnlock()
+
+	for _, r := range pathRules {
+		if strings.HasPrefix(n, r.Prefix) {
+			return r.Replacement + strings.TrimPrefix(n, r.Prefix), true
+		}
+	}
+
+	return n, false
+}
+
+// buildModules is the module information of the binary read from the build information.
+type buildModules struct {
+	main    string         // The main module path.
+	mainPkg string         // The import path of the main package.
+	deps    []debug.Module // The dependencies, replaced ones have Replace set.
+}
+
+// readBuildModules returns the module information from the binary build information.
+func readBuildModules() buildModules {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok || bi.Main.Path == "" || bi.Path == "command-line-arguments" {
+		return buildModules{}
+	}
+
+	m := buildModules{main: bi.Main.Path, mainPkg: bi.Path}
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			m.deps = append(m.deps, *dep)
+		}
+	}
+
+	return m
+}
+
+// isMainPackage returns true if the package belongs to the main module.
+func (m buildModules) isMainPackage(pkg string) bool {
+	return isModulePackage(pkg, m.main)
+}
+
+// isReplacedPackage returns true if the package belongs to a module replaced with a local directory.
+func (m buildModules) isReplacedPackage(pkg string) bool {
+	for _, dep := range m.deps {
+		if isLocalReplace(dep.Replace) && isModulePackage(pkg, dep.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trimModuleDir replaces the directory of the main module or of a module replaced with a local directory with the
+// module path. Relative replacement directories are resolved against the main module directory.
+func (m buildModules) trimModuleDir(n string) (string, bool) {
+	var mainDir string
+	if d := mainModuleDir.Load(); d != nil {
+		mainDir = *d
+	}
+	if mainDir != "" && strings.HasPrefix(n, mainDir+"/") {
+		return m.main + strings.TrimPrefix(n, mainDir), true
+	}
+
+	for _, dep := range m.deps {
+		if !isLocalReplace(dep.Replace) {
+			continue
+		}
+
+		dir := filepath.ToSlash(dep.Replace.Path)
+		if !filepath.IsAbs(dep.Replace.Path) {
+			if mainDir == "" {
+				continue
+			}
+			dir = path.Join(mainDir, dir)
+		}
+		if strings.HasPrefix(n, dir+"/") {
+			return dep.Path + strings.TrimPrefix(n, dir), true
+		}
+	}
+
+	return n, false
+}
+
+// trimVendorDir replaces the vendor directory with the path and the version of the vendored module.
+func (m buildModules) trimVendorDir(n string) (string, bool) {
+	i := strings.LastIndex(n, vendorDir)
+	if i < 0 {
+		return n, false
+	}
+
+	vendored := n[i+len(vendorDir):]
+	for _, dep := range m.deps {
+		if dep.Version != "" && strings.HasPrefix(vendored, dep.Path+"/") {
+			return dep.Path + "@" + dep.Version + strings.TrimPrefix(vendored, dep.Path), true
+		}
+	}
+
+	return n, false
+}
+
+// learnMainModuleDir stores the main module directory found from the directory of the main module package pkg.
+// The package import path is the module path followed by the package directory relative to the module one.
+func learnMainModuleDir(dir, pkg string) {
+	if mainModuleDir.Load() != nil {
+		return
+	}
+
+	dir = strings.TrimSuffix(dir, "/")
+	rel := strings.TrimPrefix(pkg, modules.main)
+	if !strings.HasSuffix(dir, rel) {
+		return
+	}
+
+	root := strings.TrimSuffix(dir, rel)
+	mainModuleDir.CompareAndSwap(nil, &root)
+}
+
+// isModulePackage returns true if the package belongs to the module.
+func isModulePackage(pkg, module string) bool {
+	return module != "" && (pkg == module || strings.HasPrefix(pkg, module+"/"))
+}
+
+// isLocalReplace returns true if the module is replaced with a local directory.
+// Unlike module paths, directories are either absolute or start with ./ or ../.
+func isLocalReplace(r *debug.Module) bool {
+	if r == nil {
+		return false
+	}
+
+	p := filepath.ToSlash(r.Path)
+
+	return filepath.IsAbs(r.Path) || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../")
+}
+
+// unescapeModulePath reverts the module cache escaping of the upper case letters.
 // Transforms:
-//     /Users/ameteiko/Projects/go/src/github.com/ameteiko/errors/errors.go -> github.com/ameteiko/errors/errors.go
-func sanitizeFilename(n string) string {
-	n = strings.TrimPrefix(n, gopath)
-	n = strings.TrimPrefix(n, goroot)
+//
+//	github.com/!burnt!sushi/toml@v1.2.0/decode.go -> github.com/BurntSushi/toml@v1.2.0/decode.go
+func unescapeModulePath(n string) string {
+	if !strings.Contains(n, "!") {
+		return n
+	}
+
+	b := new(strings.Builder)
+	for i := 0; i < len(n); i++ {
+		if n[i] == '!' && i+1 < len(n) && 'a' <= n[i+1] && n[i+1] <= 'z' {
+			i++
+			b.WriteByte(n[i] - 'a' + 'A')
+			continue
+		}
+		b.WriteByte(n[i])
+	}
 
-	return n
+	return b.String()
 }
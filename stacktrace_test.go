@@ -1,6 +1,9 @@
 package errors
 
 import (
+	"fmt"
+	"runtime/debug"
+	"strings"
 	"testing"
 )
 
@@ -26,3 +29,383 @@ func TestSanitizeFuncName(t *testing.T) {
 		})
 	}
 }
+
+// setStackConfig sets the stack mode and depth for the test duration.
+func setStackConfig(tb testing.TB, mode StackMode, depth int) {
+	tb.Helper()
+
+	prevMode, prevDepth := CurrentStackMode(), CurrentStackDepth()
+	SetStackMode(mode)
+	SetStackDepth(depth)
+	tb.Cleanup(func() {
+		SetStackMode(prevMode)
+		SetStackDepth(prevDepth)
+	})
+}
+
+func TestStackMode(t *testing.T) {
+	tcs := []struct {
+		desc       string
+		mode       StackMode
+		depth      int
+		wantFrames int
+	}{
+		{`for the full mode`, StackFull, 16, 3},
+		{`for the full mode with a limited depth`, StackFull, 2, 2},
+		{`for the caller mode`, StackCaller, 16, 1},
+		{`for the off mode`, StackOff, 16, 0},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			setStackConfig(t, tc.mode, tc.depth)
+
+			q := Wrap(New("1")).(*queue)
+
+			s, _ := q.stacktrace.(Stacktrace)
+			frames := s.Frames()
+			if tc.wantFrames == 0 && q.stacktrace != nil {
+				t.Fatalf("Wrap() in the %v mode mustn't capture a stacktrace, got %v", tc.mode, frames)
+			}
+			if len(frames) < tc.wantFrames || (tc.mode != StackFull && len(frames) != tc.wantFrames) {
+				t.Fatalf("Wrap() in the %v mode with depth %d captured %d frames", tc.mode, tc.depth, len(frames))
+			}
+			if tc.mode == StackCaller && !strings.HasSuffix(frames[0].Function, "TestStackMode.func1") {
+				t.Errorf("Wrap() in the %v mode must capture the caller, got %v", tc.mode, frames[0])
+			}
+		})
+	}
+}
+
+func TestStackModeForWithMessage(t *testing.T) {
+	setStackConfig(t, StackCaller, 16)
+
+	q := WithMessage(New("1"), "message").(*queue)
+
+	s, _ := q.stacktrace.(Stacktrace)
+	if frames := s.Frames(); len(frames) != 1 || !strings.HasSuffix(frames[0].Function, "TestStackModeForWithMessage") {
+		t.Errorf("WithMessage() in the caller mode must capture the caller, got %v", frames)
+	}
+}
+
+func TestFormatForTheOffStackMode(t *testing.T) {
+	setStackConfig(t, StackOff, 16)
+
+	if msg := fmt.Sprintf("%+v", Wrap(New("1"))); msg != "1" {
+		t.Errorf("%%+v for an error without a stacktrace != %q, got %q", "1", msg)
+	}
+}
+
+func TestSetStackDepth(t *testing.T) {
+	setStackConfig(t, StackFull, 0)
+
+	if depth := CurrentStackDepth(); depth != 1 {
+		t.Errorf("SetStackDepth(0) must set the depth to 1, got %d", depth)
+	}
+}
+
+func TestParseStackMode(t *testing.T) {
+	tcs := []struct {
+		desc   string
+		name   string
+		want   StackMode
+		wantOk bool
+	}{
+		{`for an empty name`, "", StackFull, false},
+		{`for an unknown name`, "partial", StackFull, false},
+		{`for the full mode`, "full", StackFull, true},
+		{`for the caller mode`, "caller", StackCaller, true},
+		{`for the off mode in upper case`, "OFF", StackOff, true},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			m, ok := parseStackMode(tc.name)
+			if m != tc.want || ok != tc.wantOk {
+				t.Errorf("parseStackMode(%q)=(%v, %v), got (%v, %v)", tc.name, tc.want, tc.wantOk, m, ok)
+			}
+		})
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	err := New("1")
+	for _, mode := range []StackMode{StackFull, StackCaller, StackOff} {
+		b.Run(mode.String(), func(b *testing.B) {
+			setStackConfig(b, mode, stacktraceDepth)
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = Wrap(err)
+			}
+		})
+	}
+
+	b.Run("full-64", func(b *testing.B) {
+		setStackConfig(b, StackFull, 64)
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_ = deepWrap(32, err)
+		}
+	})
+}
+
+// deepWrap wraps err on the given call stack depth.
+func deepWrap(depth int, err error) error {
+	if depth == 0 {
+		return Wrap(err)
+	}
+
+	return deepWrap(depth-1, err)
+}
+
+func TestFuncPackage(t *testing.T) {
+	tcs := []struct {
+		desc string
+		name string
+		want string
+	}{
+		{`for an empty name`, "", ""},
+		{`for a main function`, "main.main", "main"},
+		{`for a fully qualified name`, "github.com/ameteiko/errors.Wrap", "github.com/ameteiko/errors"},
+		{`for a method`, "github.com/ameteiko/errors.(*queue).Error", "github.com/ameteiko/errors"},
+		{`for a closure`, "net/http.HandlerFunc.ServeHTTP.func1", "net/http"},
+		{`for a dotted path`, "gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if p := funcPackage(tc.name); p != tc.want {
+				t.Errorf("funcPackage(%q)=%q, got %q", tc.name, tc.want, p)
+			}
+		})
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+	q := Wrap(New("1"))
+
+	tcs := []struct {
+		desc         string
+		err          error
+		wantFunction string
+	}{
+		{`for a nil error`, nil, ""},
+		{`for an error`, New("1"), ""},
+		{`for a queue`, q, "github.com/ameteiko/errors.Wrap"},
+		{`for a queue wrapped by the stdlib`, fmt.Errorf("context: %w", q), "github.com/ameteiko/errors.Wrap"},
+		{`for a queue without a stacktrace`, &queue{errs: []error{New("1")}}, ""},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			frames := StackTrace(tc.err).Frames()
+
+			if tc.wantFunction == "" {
+				if len(frames) != 0 {
+					t.Errorf("StackTrace(%v) must be empty, got %v", tc.err, frames)
+				}
+				return
+			}
+			if len(frames) < 2 {
+				t.Fatalf("StackTrace(%v) must contain the Wrap() call stack, got %v", tc.err, frames)
+			}
+
+			want := Frame{Function: tc.wantFunction, Package: "github.com/ameteiko/errors"}
+			if f := frames[0]; f.Function != want.Function || f.Package != want.Package || f.Line == 0 {
+				t.Errorf("StackTrace(%v)[0]=%+v, got %+v", tc.err, want, f)
+			}
+			if f := frames[1]; !strings.HasSuffix(f.File, "stacktrace_test.go") || f.Line == 0 {
+				t.Errorf("StackTrace(%v)[1] must point to the Wrap() caller, got %+v", tc.err, f)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	setBuildModules(t, buildModules{
+		main:    "github.com/ameteiko/errors",
+		mainPkg: "github.com/ameteiko/errors/cmd/errs",
+		deps: []debug.Module{
+			{Path: "github.com/pkg/errors", Version: "v0.9.1"},
+			{Path: "github.com/acme/lib", Version: "v1.0.0", Replace: &debug.Module{Path: "/home/ci/lib"}},
+		},
+	})
+
+	tcs := []struct {
+		desc string
+		name string
+		pkg  string
+		want string
+	}{
+		{
+			`for a GOROOT file`,
+			goroot + "net/http/server.go", "net/http",
+			"net/http/server.go",
+		},
+		{
+			`for a GOPATH file`,
+			gopath + "github.com/pkg/errors/errors.go", "",
+			"github.com/pkg/errors/errors.go",
+		},
+		{
+			`for a module cache file`,
+			"/home/ci/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go", "github.com/pkg/errors",
+			"github.com/pkg/errors@v0.9.1/errors.go",
+		},
+		{
+			`for a module cache file with upper case letters`,
+			"/home/ci/go/pkg/mod/github.com/!burnt!sushi/toml@v1.2.0/decode.go", "github.com/BurntSushi/toml",
+			"github.com/BurntSushi/toml@v1.2.0/decode.go",
+		},
+		{
+			`for a package directory`,
+			"/home/ci/src/github.com/acme/app/api/server.go", "github.com/acme/app/api",
+			"github.com/acme/app/api/server.go",
+		},
+		{
+			`for a main module file`,
+			"/home/ci/checkout/errors.go", "github.com/ameteiko/errors",
+			"github.com/ameteiko/errors/errors.go",
+		},
+		{
+			`for a main module subpackage file`,
+			"/home/ci/checkout/httperrors/problem.go", "github.com/ameteiko/errors/httperrors",
+			"github.com/ameteiko/errors/httperrors/problem.go",
+		},
+		{
+			`for a -trimpath file`,
+			"github.com/pkg/errors@v0.9.1/errors.go", "github.com/pkg/errors",
+			"github.com/pkg/errors@v0.9.1/errors.go",
+		},
+		{
+			`for a main package file`,
+			"/home/ci/checkout/cmd/errs/main.go", "main",
+			"github.com/ameteiko/errors/cmd/errs/main.go",
+		},
+		{
+			`for a locally replaced module package file`,
+			"/home/ci/lib/store/store.go", "github.com/acme/lib/store",
+			"github.com/acme/lib/store/store.go",
+		},
+		{
+			`for a locally replaced module file`,
+			"/home/ci/lib/gen.go", "",
+			"github.com/acme/lib/gen.go",
+		},
+		{
+			`for a vendored file`,
+			"/home/ci/checkout/vendor/github.com/pkg/errors/errors.go", "github.com/pkg/errors",
+			"github.com/pkg/errors@v0.9.1/errors.go",
+		},
+		{
+			`for an unknown file`,
+			"/home/ci/tools/gen.go", "",
+			"/home/ci/tools/gen.go",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if n := sanitizeFilename(tc.name, tc.pkg); n != tc.want {
+				t.Errorf("sanitizeFilename(%q, %q)=%q, got %q", tc.name, tc.pkg, tc.want, n)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameForTheMainModuleDir(t *testing.T) {
+	setBuildModules(t, buildModules{
+		main:    "github.com/acme/app",
+		mainPkg: "github.com/acme/app",
+		deps:    []debug.Module{{Path: "github.com/acme/lib", Replace: &debug.Module{Path: "../lib"}}},
+	})
+
+	tcs := []struct {
+		desc string
+		name string
+		pkg  string
+		want string
+	}{
+		{
+			`for a file before the main module directory is known`,
+			"/tmp/app/internal/gen.go", "",
+			"/tmp/app/internal/gen.go",
+		},
+		{
+			`for a relatively replaced module file before the main module directory is known`,
+			"/tmp/lib/gen.go", "",
+			"/tmp/lib/gen.go",
+		},
+		{
+			`for a main package file`,
+			"/tmp/app/main.go", "main",
+			"github.com/acme/app/main.go",
+		},
+		{
+			`for a file of the main module`,
+			"/tmp/app/internal/gen.go", "",
+			"github.com/acme/app/internal/gen.go",
+		},
+		{
+			`for a relatively replaced module file`,
+			"/tmp/lib/gen.go", "",
+			"github.com/acme/lib/gen.go",
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if n := sanitizeFilename(tc.name, tc.pkg); n != tc.want {
+				t.Errorf("sanitizeFilename(%q, %q)=%q, got %q", tc.name, tc.pkg, tc.want, n)
+			}
+		})
+	}
+}
+
+// setBuildModules sets the module information of the binary and resets the main module directory for the test.
+func setBuildModules(tb testing.TB, m buildModules) {
+	prevModules, prevDir := modules, mainModuleDir.Load()
+	modules = m
+	mainModuleDir.Store(nil)
+	tb.Cleanup(func() {
+		modules = prevModules
+		mainModuleDir.Store(prevDir)
+	})
+}
+
+func TestSetPathRules(t *testing.T) {
+	SetPathRules(
+		PathRule{Prefix: "/home/ci/checkout/", Replacement: "app/"},
+		PathRule{Prefix: "/home/ci/", Replacement: ""},
+	)
+	defer SetPathRules()
+
+	tcs := []struct {
+		desc string
+		name string
+		want string
+	}{
+		{`for the first rule`, "/home/ci/checkout/main.go", "app/main.go"},
+		{`for the second rule`, "/home/ci/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go",
+			"go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go"},
+		{`for a file that doesn't match the rules`, "/tmp/main.go", "/tmp/main.go"},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			if n := sanitizeFilename(tc.name, "main"); n != tc.want {
+				t.Errorf("sanitizeFilename(%q)=%q, got %q", tc.name, tc.want, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package errors
+
+// WrapTrail returns the call sites of the functions that have wrapped the error in the outer-to-inner order.
+// Every Wrap(), WithMessage(), WrapWithMessage() and other function creating an error queue records the location it
+// was called from, Recover() records the location of the panic. So the trail shows the path of the error through the
+// application layers even if the stacktrace was captured deep inside. The call sites are not recorded in the StackOff
+// mode, see SetStackMode().
+func WrapTrail(err error) (frames []Frame) {
+	Walk(err, func(err error, _ int) bool {
+		if q, ok := err.(*queue); ok && q.caller != 0 {
+			frames = append(frames, callerFrame(q.caller))
+		}
+
+		return true
+	})
+
+	return frames
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// trailRepository, trailService and trailHandler are the application layers wrapping an error.
+func trailRepository() error { return Wrap(New("not found")) }
+
+func trailService() error { return WithMessage(trailRepository(), "loading user") }
+
+func trailHandler() error {
+	return WrapWithMessage(trailService(), New("handler error"), "handling request")
+}
+
+func TestWrapTrail(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	tcs := []struct {
+		name      string
+		err       error
+		functions []string
+	}{
+		{
+			name:      "ForANilError",
+			err:       nil,
+			functions: nil,
+		},
+		{
+			name:      "ForAPlainError",
+			err:       New("1"),
+			functions: nil,
+		},
+		{
+			name:      "ForASingleWrap",
+			err:       trailRepository(),
+			functions: []string{"trailRepository"},
+		},
+		{
+			name:      "ForSeveralLayers",
+			err:       trailHandler(),
+			functions: []string{"trailHandler", "trailService", "trailRepository"},
+		},
+		{
+			name:      "ForAnErrorWrappedByTheStdlib",
+			err:       WithFields(fmt.Errorf("context: %w", trailRepository()), "id", 1),
+			functions: []string{"TestWrapTrail", "trailRepository"},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			trail := WrapTrail(tc.err)
+
+			if len(trail) != len(tc.functions) {
+				t.Fatalf("WrapTrail(%v) must return %d frames, got %v", tc.err, len(tc.functions), trail)
+			}
+			for i, f := range trail {
+				if !strings.HasPrefix(f.Function, errorsPackage+"."+tc.functions[i]) || f.Line == 0 ||
+					!strings.HasSuffix(f.File, "trail_test.go") || f.Package != errorsPackage {
+					t.Errorf("WrapTrail(%v)[%d] must be the call site in %s(), got %+v", tc.err, i, tc.functions[i], f)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapTrailForTheStackModes(t *testing.T) {
+	setStackConfig(t, StackCaller, stacktraceDepth)
+	if trail := WrapTrail(trailService()); len(trail) != 2 {
+		t.Errorf("WrapTrail() must return the call sites in the %v mode, got %v", StackCaller, trail)
+	}
+
+	setStackConfig(t, StackOff, stacktraceDepth)
+	if trail := WrapTrail(trailService()); trail != nil {
+		t.Errorf("WrapTrail() must return nil in the %v mode, got %v", StackOff, trail)
+	}
+}
+
+func TestFormatForTheWrapTrail(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	output := fmt.Sprintf("%+v", trailService())
+	trailIndex := strings.Index(output, "\nwrap trail:\n\t")
+	if trailIndex < 0 {
+		t.Fatalf("%%+v must print the wrap trail, got %q", output)
+	}
+	trail := strings.Split(strings.TrimSuffix(output[trailIndex:], "\n"), "\n")[2:]
+	if len(trail) != 2 || !strings.Contains(trail[0], "errors.trailService()") ||
+		!strings.Contains(trail[1], "errors.trailRepository()") {
+		t.Errorf("%%+v must print the wrap trail in the outer-to-inner order, got %q", trail)
+	}
+
+	if output := fmt.Sprintf("%+v", trailRepository()); strings.Contains(output, "wrap trail:") {
+		t.Errorf("%%+v mustn't print the wrap trail of a single wrapping call, got %q", output)
+	}
+}
+
+func TestFormatForTheWrapTrailWithoutAStacktrace(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	caller := Wrap(New("1")).(*queue).caller
+	q := &queue{errs: []error{&queue{errs: []error{New("1")}, caller: caller}}, caller: caller}
+
+	if output := fmt.Sprintf("%+v", q); !strings.HasPrefix(output, "1\nwrap trail:\n\t") {
+		t.Errorf("%%+v must print the wrap trail on a new line, got %q", output)
+	}
+}
+
+func TestWrapTrailForARecoveredPanic(t *testing.T) {
+	setStackConfig(t, StackFull, stacktraceDepth)
+
+	trail := WrapTrail(WithMessage(recoverPanic(nil, "boom"), "message"))
+
+	if len(trail) != 2 || !strings.HasSuffix(trail[0].Function, ".TestWrapTrailForARecoveredPanic") ||
+		!strings.HasSuffix(trail[1].Function, ".panicWith") {
+		t.Errorf("WrapTrail() must contain the location of the panic, got %v", trail)
+	}
+}